@@ -3,37 +3,79 @@ package main
 import (
 	"fmt"
 	"image/color"
+	"math"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/lcaballero/ebiten-01/shapes"
+	"github.com/lcaballero/ebiten-01/sim"
 )
 
 type Background struct {
 	ctx     Context
 	w, h    int
-	scoring ScoreBoard
+	size    float64 // pixels per cell
+	scoring sim.ScoreBoard
+	clear   []string // describes the last clear over the board
+	status  string   // progress toward the goal of the mode
+	grid    bool     // draws a line between each column of the board
 	canvas  shapes.Rect
 	board   shapes.Rect
 	next    shapes.Rect
+	queue   []shapes.Rect // smaller previews after the next piece
+	hold    shapes.Rect
 	score   shapes.Rect
 	level   shapes.Rect
 	lines   shapes.Rect
 }
 
-func NewBackground() *Background {
+// NewBackground lays out the HUD to the right of a board of cols by
+// rows cells, each size pixels square, with room for the number of
+// upcoming pieces that are previewed
+func NewBackground(cols, rows int, size float64, previews int) *Background {
+	board := shapes.NewRectAt(20, 20, float64(cols)*size, float64(rows)*size)
+	x := board.MaxX() + 50
+	bottom := math.Max(board.MaxY(), 220)
+	next := shapes.NewRectAt(x, 20, 60, 60)
 	return &Background{
-		scoring: ScoreBoard{Score: 0, Lines: 0, Level: 1},
-		canvas:  shapes.NewRectAt(0, 0, 640, 480),
-		board:   shapes.NewRectAt(20, 20, 100, 200),
-		next:    shapes.NewRectAt(170, 20, 60, 60),
-		score:   shapes.NewRectAt(170, 110, 120, 20),
-		level:   shapes.NewRectAt(170, 150, 120, 20),
-		lines:   shapes.NewRectAt(170, 190, 120, 20),
+		scoring: sim.ScoreBoard{Score: 0, Lines: 0, Level: 1},
+		size:    size,
+		canvas:  shapes.NewRectAt(0, 0, x+150, bottom+20),
+		board:   board,
+		next:    next,
+		queue:   queueRects(next, previews-1, bottom),
+		hold:    shapes.NewRectAt(x+70, 20, 60, 60),
+		score:   shapes.NewRectAt(x+70, 110, 70, 20),
+		level:   shapes.NewRectAt(x+70, 150, 70, 20),
+		lines:   shapes.NewRectAt(x+70, 190, 70, 20),
 	}
 }
 
-func (b *Background) reset() {
-	b.scoring = ScoreBoard{Score: 0, Lines: 0, Level: 1}
+// Layout is the size of the screen in pixels that fits the board and
+// the HUD
+func (b *Background) Layout() (w, h int) {
+	return int(b.canvas.W()), int(b.canvas.H())
+}
+
+// queueRects stacks n boxes below the next box down to bottom,
+// shrinking them as the queue grows so they always fit
+func queueRects(next shapes.Rect, n int, bottom float64) []shapes.Rect {
+	rects := []shapes.Rect{}
+	if n <= 0 {
+		return rects
+	}
+	top := next.MaxY() + 10
+	h := math.Min(40, (bottom-top)/float64(n))
+	for i := 0; i < n; i++ {
+		y := top + float64(i)*h
+		rects = append(rects, shapes.NewRectAt(next.X(), y, next.W(), h-4))
+	}
+	return rects
+}
+
+// previewSize is the pixel size of a cell drawn in the preview box,
+// where pieces are at most 2 cells high in the spawn rotation
+func (b *Background) previewSize(box shapes.Rect) float64 {
+	return math.Min(b.size, box.H()/3)
 }
 
 func (b *Background) Draw(screen *ebiten.Image) {
@@ -59,6 +101,7 @@ func (b *Background) copy(screen *ebiten.Image) {
 func (b *Background) labels(ctx Context) {
 	ls := shapes.Vec{0, -2}
 	ctx.Text("Next", b.next.Pos.Add(ls))
+	ctx.Text("Hold", b.hold.Pos.Add(ls))
 	ctx.Text("Score", b.score.Pos.Add(ls))
 	ctx.Text("Level", b.level.Pos.Add(ls))
 	ctx.Text("Lines", b.lines.Pos.Add(ls))
@@ -70,6 +113,12 @@ func (b *Background) labels(ctx Context) {
 	ctx.Text(score, b.score.Pos.Add(ls))
 	ctx.Text(level, b.level.Pos.Add(ls))
 	ctx.Text(lines, b.lines.Pos.Add(ls))
+
+	ctx.Text(b.status, b.lines.Pos.Add(shapes.Vec{0, 38}))
+
+	for i, line := range b.clear {
+		ctx.Text(line, b.board.Pos.Add(shapes.Vec{4, float64(i+1) * 12}))
+	}
 }
 
 func (b *Background) bg(ctx Context) {
@@ -83,10 +132,24 @@ func (b *Background) bg(ctx Context) {
 	ctx.DrawRectangle(b.board)
 	ctx.Fill()
 
+	if b.grid {
+		b.gridLines(ctx)
+	}
+
 	ctx.SetColor(color.Black)
 	ctx.DrawRectangle(b.next)
 	ctx.Fill()
 
+	for _, q := range b.queue {
+		ctx.SetColor(color.Black)
+		ctx.DrawRectangle(q)
+		ctx.Fill()
+	}
+
+	ctx.SetColor(color.Black)
+	ctx.DrawRectangle(b.hold)
+	ctx.Fill()
+
 	ctx.SetColor(color.Black)
 	ctx.DrawRectangle(b.score)
 	ctx.Fill()
@@ -99,3 +162,16 @@ func (b *Background) bg(ctx Context) {
 	ctx.DrawRectangle(b.lines)
 	ctx.Fill()
 }
+
+// gridLines draws faint lines between the columns of the board to
+// line up pieces with the stack
+func (b *Background) gridLines(ctx Context) {
+	gray := color.RGBA{R: 40, G: 40, B: 40, A: 255}
+	cols := int(math.Round(b.board.W() / b.size))
+	ctx.SetColor(gray)
+	for c := 1; c < cols; c++ {
+		x := b.board.X() + float64(c)*b.size
+		ctx.DrawRectangle(shapes.NewRectAt(x, b.board.Y(), 1, b.board.H()))
+		ctx.Fill()
+	}
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/lcaballero/ebiten-01/sim"
+	"gopkg.in/yaml.v3"
+)
+
+// controlNames lists every control that can be bound, named after its
+// sim.Action or Command, in the order they are shown
+var controlNames = []string{
+	sim.MoveLeft.String(),
+	sim.MoveRight.String(),
+	sim.SoftDrop.String(),
+	sim.HardDrop.String(),
+	sim.RotateCW.String(),
+	sim.RotateCCW.String(),
+	sim.Rotate180.String(),
+	sim.Hold.String(),
+	sim.ResetPiece.String(),
+	sim.Pause.String(),
+	sim.Restart.String(),
+	Quit.String(),
+	ToggleGhost.String(),
+	PlayJab.String(),
+}
+
+// Bindings maps the name of each control to the keys that trigger it
+type Bindings map[string][]ebiten.Key
+
+func DefaultBindings() Bindings {
+	return Bindings{
+		sim.MoveLeft.String():   {ebiten.KeyJ, ebiten.KeyArrowLeft},
+		sim.MoveRight.String():  {ebiten.KeyL, ebiten.KeyArrowRight},
+		sim.SoftDrop.String():   {ebiten.KeyK, ebiten.KeyArrowDown},
+		sim.HardDrop.String():   {ebiten.KeyI, ebiten.KeyArrowUp},
+		sim.RotateCW.String():   {ebiten.KeySpace, ebiten.KeyX},
+		sim.RotateCCW.String():  {ebiten.KeyU, ebiten.KeyZ},
+		sim.Rotate180.String():  {ebiten.KeyO},
+		sim.Hold.String():       {ebiten.KeyH, ebiten.KeyC},
+		sim.ResetPiece.String(): {ebiten.KeyR},
+		sim.Pause.String():      {ebiten.KeyP, ebiten.KeyEscape},
+		sim.Restart.String():    {ebiten.Key0},
+		Quit.String():           {ebiten.KeyQ},
+		ToggleGhost.String():    {ebiten.KeyG},
+		PlayJab.String():        {ebiten.Key1},
+	}
+}
+
+// WASDBindings move with the left hand on WASD and the arrow keys,
+// leaving the keys around them to rotate and hold
+func WASDBindings() Bindings {
+	return Bindings{
+		sim.MoveLeft.String():   {ebiten.KeyA, ebiten.KeyArrowLeft},
+		sim.MoveRight.String():  {ebiten.KeyD, ebiten.KeyArrowRight},
+		sim.SoftDrop.String():   {ebiten.KeyS, ebiten.KeyArrowDown},
+		sim.HardDrop.String():   {ebiten.KeySpace},
+		sim.RotateCW.String():   {ebiten.KeyW, ebiten.KeyArrowUp},
+		sim.RotateCCW.String():  {ebiten.KeyQ},
+		sim.Rotate180.String():  {ebiten.KeyE},
+		sim.Hold.String():       {ebiten.KeyShift, ebiten.KeyC},
+		sim.ResetPiece.String(): {ebiten.KeyR},
+		sim.Pause.String():      {ebiten.KeyP, ebiten.KeyEscape},
+		sim.Restart.String():    {ebiten.Key0},
+		Quit.String():           {ebiten.KeyF10},
+		ToggleGhost.String():    {ebiten.KeyG},
+		PlayJab.String():        {ebiten.Key1},
+	}
+}
+
+const (
+	DefaultPreset = "default"
+	WASDPreset    = "wasd"
+)
+
+// Presets lists the controls that are built in, by name
+var Presets = []string{DefaultPreset, WASDPreset}
+
+// presetControls provides the controls of the preset, reporting false
+// when there is no preset by that name
+func presetControls(name string) (Controls, bool) {
+	c := DefaultControls()
+	switch name {
+	case DefaultPreset:
+		return c, true
+	case WASDPreset:
+		c.Keys = WASDBindings()
+		return c, true
+	default:
+		return c, false
+	}
+}
+
+// DefaultDeadzone is how far the stick is pushed before it moves the
+// piece, ignoring the drift of worn sticks
+const DefaultDeadzone = 0.25
+
+// Controls binds the keys and the gamepad buttons of each control
+type Controls struct {
+	Keys     Bindings    `yaml:",inline"`
+	Gamepad  PadBindings `yaml:"gamepad"`
+	Deadzone float64     `yaml:"deadzone"` // of the left stick, from 0 to 1
+}
+
+func DefaultControls() Controls {
+	return Controls{
+		Keys:     DefaultBindings(),
+		Gamepad:  DefaultPadBindings(),
+		Deadzone: DefaultDeadzone,
+	}
+}
+
+// ParseControls reads the controls from YAML, or JSON, naming the keys
+// for each control, along with the gamepad buttons, such as:
+//
+//	move-left: [A, ArrowLeft]
+//	rotate-cw: [W]
+//	gamepad:
+//	  hold: [lb]
+//	deadzone: 0.3
+//
+// Controls that are left out keep their default keys and buttons,
+// less any the file binds to other controls.
+func ParseControls(data []byte) (Controls, error) {
+	read := Controls{Keys: Bindings{}, Gamepad: PadBindings{}, Deadzone: DefaultDeadzone}
+	err := yaml.Unmarshal(data, &read)
+	if err != nil {
+		return Controls{}, fmt.Errorf("reading controls: %w", err)
+	}
+	c := DefaultControls()
+	c.Deadzone = read.Deadzone
+	if c.Deadzone < 0 || c.Deadzone >= 1 {
+		return Controls{}, fmt.Errorf("deadzone %v is not from 0 up to 1", c.Deadzone)
+	}
+	for name := range read.Keys {
+		if err := knownControl(name); err != nil {
+			return Controls{}, err
+		}
+	}
+	for name := range read.Gamepad {
+		if err := knownControl(name); err != nil {
+			return Controls{}, err
+		}
+	}
+	if err := read.Keys.check(); err != nil {
+		return Controls{}, err
+	}
+	if err := read.Gamepad.check(); err != nil {
+		return Controls{}, err
+	}
+	for name, keys := range read.Keys {
+		c.Keys.unbind(keys)
+		c.Keys[name] = keys
+	}
+	for name, buttons := range read.Gamepad {
+		c.Gamepad.unbind(buttons)
+		c.Gamepad[name] = buttons
+	}
+	return c, nil
+}
+
+func knownControl(name string) error {
+	for _, n := range controlNames {
+		if n == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown control %q, expected one of %s", name, strings.Join(controlNames, ", "))
+}
+
+// check makes sure no key triggers more than one control
+func (b Bindings) check() error {
+	bound := map[ebiten.Key]string{}
+	for _, name := range controlNames {
+		for _, k := range b[name] {
+			if other, ok := bound[k]; ok {
+				return fmt.Errorf("key %s is bound to both %s and %s", k, other, name)
+			}
+			bound[k] = name
+		}
+	}
+	return nil
+}
+
+// unbind removes the keys from every control, so that they can be
+// bound to another
+func (b Bindings) unbind(keys []ebiten.Key) {
+	for name, bound := range b {
+		kept := []ebiten.Key{}
+		for _, k := range bound {
+			if !hasKey(keys, k) {
+				kept = append(kept, k)
+			}
+		}
+		b[name] = kept
+	}
+}
+
+func hasKey(keys []ebiten.Key, k ebiten.Key) bool {
+	for _, key := range keys {
+		if key == k {
+			return true
+		}
+	}
+	return false
+}
+
+// Write lists the keys and buttons of each control
+func (c Controls) Write(w io.Writer) error {
+	for _, name := range controlNames {
+		keys := []string{}
+		for _, k := range c.Keys[name] {
+			keys = append(keys, k.String())
+		}
+		buttons := []string{}
+		for _, b := range c.Gamepad[name] {
+			buttons = append(buttons, b.String())
+		}
+		line := fmt.Sprintf("%-13s %-22s %s", name, strings.Join(keys, ", "), strings.Join(buttons, ", "))
+		_, err := fmt.Fprintln(w, strings.TrimSpace(line))
+		if err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%-13s %v\n", "deadzone", c.Deadzone)
+	return err
+}
+
+// MustLoadControls reads the controls file, or provides the preset
+// controls by name, with the defaults when no file is given
+func MustLoadControls(path string) Controls {
+	if c, ok := presetControls(path); ok {
+		return c
+	}
+	if path == "" {
+		return DefaultControls()
+	}
+	log.Printf("loading controls: %s", path)
+	bin, err := os.ReadFile(path)
+	if err != nil {
+		panic(err)
+	}
+	c, err := ParseControls(bin)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
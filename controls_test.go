@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/lcaballero/ebiten-01/sim"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseControls(t *testing.T) {
+	table := []struct {
+		name     string
+		data     string
+		left     []ebiten.Key
+		hold     []ebiten.Key
+		padHold  []PadButton
+		deadzone float64
+		fails    bool
+	}{
+		{
+			name:     "empty keeps the defaults",
+			data:     "",
+			left:     []ebiten.Key{ebiten.KeyJ, ebiten.KeyArrowLeft},
+			hold:     []ebiten.Key{ebiten.KeyH, ebiten.KeyC},
+			padHold:  []PadButton{PadLB, PadRB},
+			deadzone: DefaultDeadzone,
+		},
+		{
+			name:     "yaml",
+			data:     "move-left: [A]\nhold: [shift]\ngamepad:\n  hold: [lt]\ndeadzone: 0.4\n",
+			left:     []ebiten.Key{ebiten.KeyA},
+			hold:     []ebiten.Key{ebiten.KeyShift},
+			padHold:  []PadButton{PadLT},
+			deadzone: 0.4,
+		},
+		{
+			name:     "json",
+			data:     `{"move-left": ["A", "ArrowLeft"], "gamepad": {"hold": ["rt"]}}`,
+			left:     []ebiten.Key{ebiten.KeyA, ebiten.KeyArrowLeft},
+			hold:     []ebiten.Key{ebiten.KeyH, ebiten.KeyC},
+			padHold:  []PadButton{PadRT},
+			deadzone: DefaultDeadzone,
+		},
+		{
+			name:     "rebinding a default key takes it from its control",
+			data:     "rotate-cw: [J]\nmove-left: [H]\n",
+			left:     []ebiten.Key{ebiten.KeyH},
+			hold:     []ebiten.Key{ebiten.KeyC},
+			padHold:  []PadButton{PadLB, PadRB},
+			deadzone: DefaultDeadzone,
+		},
+		{
+			name:     "rebinding a default button takes it from its control",
+			data:     "gamepad:\n  hold: [a, lb]\n",
+			left:     []ebiten.Key{ebiten.KeyJ, ebiten.KeyArrowLeft},
+			hold:     []ebiten.Key{ebiten.KeyH, ebiten.KeyC},
+			padHold:  []PadButton{PadA, PadLB},
+			deadzone: DefaultDeadzone,
+		},
+		{name: "unknown control", data: "fly: [F]", fails: true},
+		{name: "unknown key", data: "hold: [Nope]", fails: true},
+		{name: "key bound twice", data: "move-left: [H]\nhold: [H]", fails: true},
+		{name: "unknown gamepad control", data: "gamepad:\n  fly: [a]", fails: true},
+		{name: "unknown button", data: "gamepad:\n  hold: [z]", fails: true},
+		{name: "button bound twice", data: "gamepad:\n  hold: [a]\n  rotate-cw: [a]", fails: true},
+		{name: "deadzone too large", data: "deadzone: 1", fails: true},
+	}
+	for _, row := range table {
+		t.Run(row.name, func(t *testing.T) {
+			c, err := ParseControls([]byte(row.data))
+			if row.fails {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, row.left, c.Keys[sim.MoveLeft.String()])
+			assert.Equal(t, row.hold, c.Keys[sim.Hold.String()])
+			assert.Equal(t, row.padHold, c.Gamepad[sim.Hold.String()])
+			assert.Equal(t, row.deadzone, c.Deadzone)
+			assert.NoError(t, c.Keys.check())
+			assert.NoError(t, c.Gamepad.check())
+		})
+	}
+}
+
+func Test_DefaultControls(t *testing.T) {
+	c := DefaultControls()
+	assert.NoError(t, c.Keys.check())
+	assert.NoError(t, c.Gamepad.check())
+	assert.Len(t, c.Keys, len(controlNames))
+	for _, name := range controlNames {
+		assert.NotEmpty(t, c.Keys[name], name)
+	}
+}
+
+func Test_Presets(t *testing.T) {
+	for _, name := range Presets {
+		c, ok := presetControls(name)
+		assert.True(t, ok, name)
+		assert.NoError(t, c.Keys.check(), name)
+		assert.Len(t, c.Keys, len(controlNames), name)
+		assert.Equal(t, c, MustLoadControls(name))
+	}
+	_, ok := presetControls("mine.yaml")
+	assert.False(t, ok)
+}
+
+func Test_Controls_Write(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, DefaultControls().Write(buf))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, len(controlNames)+1)
+	assert.Equal(t, "move-left     J, ArrowLeft           left", lines[0])
+	assert.Equal(t, "quit          Q", lines[11])
+	assert.Equal(t, "deadzone      0.25", lines[len(lines)-1])
+}
+
+func Test_NewKBHandler(t *testing.T) {
+	c, err := ParseControls([]byte("move-left: [A]\nrotate-cw: [W]\nquit: [Escape]\npause: [P]\n"))
+	assert.NoError(t, err)
+	h := NewKBHandler(c.Keys)
+	assert.Equal(t, []ebiten.Key{ebiten.KeyA}, h.held[sim.MoveLeft.String()])
+	assert.Equal(t, sim.RotateCW, h.actions[ebiten.KeyW])
+	assert.Equal(t, Quit, h.commands[ebiten.KeyEscape])
+	_, ok := h.actions[ebiten.KeyA]
+	assert.False(t, ok, "moves repeat while held rather than on a press")
+}
@@ -1,142 +1,286 @@
 package main
 
 import (
+	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/lcaballero/ebiten-01/rand"
-	"github.com/lcaballero/ebiten-01/shapes"
+	"github.com/lcaballero/ebiten-01/sim"
 )
 
+// Game adapts the headless sim.Sim to ebiten by turning key and button
+// presses into actions, playing audio for events and drawing the state.
+// Update and Draw are handed to the active Scene.
 type Game struct {
-	opts       NewGameOpts
-	board      *Board
-	background *Background
-	keys       *KBHandler
-	audio      *Audio
-
-	prev    time.Time
-	elapsed time.Duration // time elapsed during last frame
+	opts         NewGameOpts
+	cfg          sim.Config            // rules that the options are applied to
+	settings     Options               // saved to the settings file
+	flags        func(Options) Options // overrides the settings with the flags
+	options      Options               // the settings with the flags applied
+	softDrop     *sim.RepeatRules      // repeats soft drop when set
+	settingsPath string
+	scene        Scene
+	menu         *MenuReader
+	sim          *sim.Sim
+	background   *Background
+	pieces       *Pieces
+	input        *Inputs
+	queue        *sim.ActionQueue
+	audio        *Audio
+	clear        sim.Clear       // last clear shown on the HUD
+	clearTime    time.Duration   // how long the last clear has been shown
+	record       bool            // saves the recording to the record flag
+	recorder     *sim.Recorder   // records the inputs when set
+	scores       *sim.HighScores // kept when set
+	scoresPath   string
+	replay       *sim.Replay // watched instead of playing when set
+	player       *sim.Player // plays back the replay instead of the keys
+
+	tick    time.Duration // fixed duration of each Update
 	accum   time.Duration
 	seconds time.Duration
 	frames  int
-	paused  bool
-	showFPS bool
-	rnd     rand.Rnd
-
-	// game pieces
-	pieces  *Pieces
-	current *Tetromino
-	next    *Tetromino
+	quit    bool
 }
 
 func NewGame(opts NewGameOpts) *Game {
 	p := NewPieces()
-	seed := Seed(opts.Seed())
-	audio := MustLoadAudio()
-	rnd := rand.NewRnd(seed)
-	bg := NewBackground()
+	cfg := gameConfig(opts, p.Len())
+	size := float64(blockSize)
+	if opts.HasCellSize() && opts.CellSize() > 0 {
+		size = float64(opts.CellSize())
+	}
+	settings, path := loadSettings()
+	flags := func(o Options) Options {
+		return o.withFlags(opts).clamp()
+	}
+	game := newGame(cfg, size, p, settings, flags)
+	game.opts = opts
+	game.settingsPath = path
+	game.scores, game.scoresPath = loadHighScores()
+	game.record = opts.HasRecord()
+	game.softDrop = softDropRules(opts)
+	game.input = game.newInputs()
+	if opts.HasMode() {
+		game.start(sim.ToMode(opts.Mode()))
+	}
+	return game
+}
+
+// NewReplayGame plays back the recorded game instead of reading the
+// controls for moves
+func NewReplayGame(opts ReplayOpts, replay sim.Replay) *Game {
+	p := NewPieces()
+	settings, _ := loadSettings()
+	flags := func(o Options) Options {
+		if opts.HasGhost() {
+			o.Ghost = opts.Ghost()
+		}
+		if opts.HasShowFps() {
+			o.ShowFPS = opts.ShowFps()
+		}
+		return o
+	}
+	game := newGame(replay.Config, blockSize, p, settings, flags)
+	game.replay = &replay
+	game.scores, _ = loadHighScores()
+	game.start(replay.Config.Mode)
+	return game
+}
+
+// softDropRules reads how soft drop repeats from the flags, or nil
+// when soft drop speeds up gravity instead
+func softDropRules(opts NewGameOpts) *sim.RepeatRules {
+	if !opts.HasSoftDropDas() && !opts.HasSoftDropArr() {
+		return nil
+	}
+	return &sim.RepeatRules{
+		DAS: MustParseRepeat(opts.SoftDropDas()),
+		ARR: MustParseRepeat(opts.SoftDropArr()),
+	}
+}
+
+// newInputs reads the keyboard and gamepads with the controls chosen
+// by the options, repeating left and right by the options and soft
+// drop by the flags
+func (b *Game) newInputs() *Inputs {
+	shift := sim.NewShifter(b.options.repeat(), b.cfg.Cols)
+	var drop *sim.Shifter
+	if b.softDrop != nil {
+		drop = sim.NewShifter(*b.softDrop, b.cfg.Rows+b.cfg.Hidden)
+	}
+	c := MustLoadControls(b.options.Controls)
+	keys := NewKBHandler(c.Keys)
+	pads := NewGamepadHandler(ebitenGamepads{}, c.Gamepad, c.Deadzone)
+	return NewInputs(shift, drop, keys, pads)
+}
+
+// gameConfig builds the rules of the game from the flags
+func gameConfig(opts NewGameOpts, skins int) sim.Config {
+	cfg := sim.DefaultConfig(Seed(opts.Seed()))
+	cfg.Tick = time.Second / time.Duration(ebiten.TPS())
+	cfg.Skins = skins
+	if opts.HasGravity() {
+		cfg.Gravity = MustLoadGravity(opts.Gravity())
+	}
+	if opts.HasLockDelay() {
+		cfg.Lock.Delay = time.Duration(opts.LockDelay()) * time.Millisecond
+	}
+	if opts.HasLockResets() {
+		cfg.Lock.Resets = opts.LockResets()
+	}
+	if opts.StepReset() {
+		cfg.Lock.Mode = sim.StepReset
+	}
+	if opts.HasRandomizer() {
+		cfg.Randomizer = sim.ToRandomizerKind(opts.Randomizer())
+	}
+	if opts.HasRepeatPiece() {
+		cfg.RepeatPiece = sim.ToTetro(opts.RepeatPiece())
+	}
+	if opts.HasCols() {
+		cfg.Cols = opts.Cols()
+	}
+	if opts.HasRows() {
+		cfg.Rows = opts.Rows()
+	}
+	if opts.HasHiddenRows() {
+		cfg.Hidden = opts.HiddenRows()
+	}
+	return sim.ClampConfig(cfg)
+}
+
+func newGame(cfg sim.Config, size float64, p *Pieces, settings Options, flags func(Options) Options) *Game {
+	options := flags(settings)
 	game := &Game{
-		opts:       opts,
-		background: bg,
-		board:      NewBoard(bg.board),
-		keys:       NewKBHandler(),
-		prev:       time.Now(),
+		cfg:        cfg,
+		settings:   settings,
+		flags:      flags,
+		options:    options,
+		menu:       NewMenuReader(ebitenGamepads{}),
+		sim:        sim.NewSim(options.apply(cfg)),
+		background: NewBackground(cfg.Cols, cfg.Rows, size, options.Previews),
 		pieces:     p,
-		rnd:        rnd,
-		audio:      audio,
-		showFPS:    opts.ShowFps(),
+		queue:      sim.NewActionQueue(),
+		audio:      MustLoadAudio(),
+		tick:       cfg.Tick,
+		clearTime:  clearShown,
 	}
-	game.createStartPeice()
-	game.createNextPeice()
+	game.background.grid = options.Grid
+	game.audio.SetVolumes(options.volumes())
+	game.subscribe(game.sim.Bus())
+	game.scene = newTitleScene(game)
 	return game
 }
 
-func (b *Game) velocity() shapes.Vec {
-	return b.background.scoring.Velocity()
+// start plays a new game of the mode with the options chosen, at the
+// gravity of the mode unless the flag picked one, recording it for the
+// high scores, or watches the replay again
+func (b *Game) start(mode sim.Mode) {
+	cfg := b.options.apply(b.cfg)
+	cfg.Mode = mode
+	switch {
+	case b.replay != nil:
+		cfg = b.replay.Config
+		b.player = sim.NewPlayer(*b.replay)
+	case !b.opts.HasGravity():
+		cfg.Gravity = mode.Gravity()
+	}
+	b.sim = sim.NewSim(cfg)
+	b.subscribe(b.sim.Bus())
+	b.background = NewBackground(cfg.Cols, cfg.Rows, b.background.size, cfg.Previews)
+	b.background.grid = b.options.Grid
+	b.input = b.newInputs()
+	b.clearTime = clearShown
+	if b.replay == nil {
+		b.recorder = sim.NewRecorder(cfg)
+	}
+	b.switchTo(newPlayScene(b))
 }
 
-func (b *Game) top() shapes.Vec {
-	//return b.background.board.Pos.Add(b.background.board.Dim.VecX().Half())
-	return shapes.Vec{20 + 40, 20}
+// applySettings puts the settings changed on the options screen into
+// effect and saves them for the next run
+func (b *Game) applySettings() {
+	b.options = b.flags(b.settings)
+	b.audio.SetVolumes(b.options.volumes())
+	if b.settingsPath == "" {
+		return
+	}
+	err := SaveSettings(b.settingsPath, b.settings)
+	if err != nil {
+		log.Printf("saving settings: %v", err)
+		return
+	}
+	log.Printf("saved settings: %s", b.settingsPath)
 }
 
-func (b *Game) createStartPeice() {
-	b.current = &Tetromino{
-		image:    b.pieces.blocks.Pick(b.rnd),
-		pos:      b.top(),
-		tetro:    RandTetro(b.rnd),
-		rot:      R1,
-		velocity: b.velocity(),
-		size:     10,
-	}
+// switchTo makes the scene active from the next tick, forgetting the
+// presses that led to the switch so they do not carry into it.  The
+// music only plays during play.
+func (b *Game) switchTo(s Scene) {
+	b.scene = s
+	_, playing := s.(*playScene)
+	b.audio.PlayMusic(playing)
+	b.menu.Skip()
+	b.input.Pressed(sim.NewActionQueue())
+}
+
+// subscribe wires audio, the HUD and logging to the events of the sim
+func (b *Game) subscribe(bus *sim.Bus) {
+	bus.Subscribe(b.audio.OnEvent)
+	bus.Subscribe(b.showClear, sim.PieceLocked)
+	bus.Subscribe(b.gameOver, sim.GameOver)
 }
 
-func (b *Game) createNextPeice() {
-	next := &Tetromino{
-		image:    b.pieces.blocks.Pick(b.rnd),
-		tetro:    RandTetro(b.rnd),
-		rot:      R1,
-		velocity: b.velocity(),
-		size:     10,
+// showClear puts the clear scored by the locked piece on the HUD
+func (b *Game) showClear(ev sim.Event) {
+	if ev.Clear.Name() != "" {
+		b.clear = ev.Clear
+		b.clearTime = 0
 	}
-	score := shapes.Rect{
-		Pos: shapes.Vec{170, 20},
-		Dim: shapes.Vec{60, 60},
+}
+
+func (b *Game) gameOver(ev sim.Event) {
+	log.Printf("game over (%s), score: %d, lines: %d, level: %d",
+		ev.TopOut, ev.Score.Score, ev.Score.Lines, ev.Score.Level)
+}
+
+// actions queues the controls pressed, and the held moves and soft
+// drop, reporting the actions for the next tick in order
+func (b *Game) actions() []sim.Action {
+	for _, c := range b.input.Pressed(b.queue) {
+		b.command(c)
 	}
-	b.next = next.MoveCenterTo(score.Center())
-	if b.opts.HasRepeatPiece() {
-		b.next.tetro = ToTetro(b.opts.RepeatPiece())
-		b.current.tetro = b.next.tetro
+	if !b.sim.Paused() && !b.sim.IsOver() {
+		b.queue.Push(b.input.Shift(b.tick)...)
+		b.queue.Push(b.input.Drop(b.tick)...)
 	}
+	return b.queue.Drain()
 }
 
-func (b *Game) rotateInNextPeice() {
-	b.next.pos = b.top()
-	b.current = b.next
-	b.createNextPeice()
+// advance records the actions and steps the sim with them by a tick
+func (b *Game) advance(actions ...sim.Action) {
+	if b.recorder != nil {
+		b.recorder.Record(b.sim.Tick(), actions)
+	}
+	b.sim.Step(actions...)
 }
 
-func (b *Game) restart() {
-	b.background.reset()
-	b.board.reset()
-	b.createStartPeice()
-	b.createNextPeice()
+// command carries out the commands handled by the window
+func (b *Game) command(c Command) {
+	switch c {
+	case Quit:
+		b.quit = true
+	case ToggleGhost:
+		b.options.Ghost = !b.options.Ghost
+	case PlayJab:
+		b.audio.Play(EffectLock)
+	}
 }
 
 func (b *Game) step(elapsed time.Duration) {
 	b.accum += elapsed
-	select {
-	case key := <-b.keys.handler:
-		switch key {
-		case ebiten.KeyL:
-			if b.board.CanGoRight(b.current) {
-				b.current.MoveRight()
-			}
-		case ebiten.KeyJ:
-			if b.board.CanGoLeft(b.current) {
-				b.current.MoveLeft()
-			}
-		case ebiten.KeySpace:
-			if b.board.CanRotate(b.current) {
-				b.current.RotateRight()
-			}
-		case ebiten.KeyR:
-			b.current.pos = b.top()
-			b.current.isFrozen = false
-		case ebiten.KeyP:
-			b.paused = !b.paused
-		case ebiten.KeyK:
-			b.current.Accelerate()
-		case ebiten.Key1:
-			b.audio.jab.Play()
-		case ebiten.Key0:
-			b.restart()
-		}
-	default:
-	}
 	ds := b.accum - b.seconds
 	hasTics := ds > time.Second
 	for ds > time.Second {
@@ -144,7 +288,7 @@ func (b *Game) step(elapsed time.Duration) {
 		ds -= time.Second
 	}
 	if hasTics {
-		if b.showFPS {
+		if b.options.ShowFPS {
 			log.Printf("fps: %d", b.frames)
 		}
 		b.frames = 0
@@ -152,39 +296,61 @@ func (b *Game) step(elapsed time.Duration) {
 }
 
 func (b *Game) Update() error {
-	b.elapsed = time.Since(b.prev)
-	b.prev = time.Now()
-	b.step(b.elapsed)
-	ds := float64(b.elapsed) / float64(time.Second)
-	if !b.paused {
-		b.current.Update(b.elapsed, ds)
+	b.step(b.tick)
+	err := b.scene.Update()
+	if b.quit {
+		return ebiten.Termination
 	}
-	if ebiten.IsKeyPressed(ebiten.KeyQ) {
-		os.Exit(1)
+	return err
+}
+
+func (b *Game) Draw(screen *ebiten.Image) {
+	b.scene.Draw(screen)
+	b.frames++
+}
+
+// drawPlay draws the playfield and the HUD at the current tick
+func (b *Game) drawPlay(screen *ebiten.Image) {
+	state := b.sim.State()
+	b.background.scoring = state.Score
+	b.background.status = status(state)
+	b.background.clear = nil
+	if b.clearTime < clearShown {
+		b.background.clear = clearLabels(b.clear)
 	}
-	b.keys.Update(b.paused, b.elapsed)
-	b.board.CheckBounds(b.current)
-	if b.current.isFrozen {
-		rows := b.board.ClearFullRows(b.current)
-		b.background.scoring = b.background.scoring.Add(len(rows))
-		b.rotateInNextPeice()
-		b.audio.jab.Play()
+	b.background.Draw(screen)
+	board := b.visibleBoard(screen)
+	b.drawMarks(board, state.Marks)
+	if b.options.Ghost && !state.Over {
+		b.drawGhost(board, state.Ghost)
 	}
-	if b.board.IsGameOver() {
-		log.Printf("game over")
-		b.paused = true
+	b.drawPiece(board, state.Current)
+	b.drawQueue(screen, state.Queue)
+	if state.Hold != nil {
+		b.drawPreview(screen, *state.Hold, b.background.hold)
 	}
-	return nil
 }
 
-func (b *Game) Draw(screen *ebiten.Image) {
-	b.background.Draw(screen)
-	b.board.Draw(screen)
-	b.current.Draw(screen)
-	b.next.Draw(screen)
-	b.frames++
+// clearShown is how long a clear stays on the HUD
+const clearShown = 2 * time.Second
+
+// clearLabels describes the clear with a line for each part of the
+// bonus that was scored
+func clearLabels(c sim.Clear) []string {
+	labels := []string{}
+	if c.BackToBack {
+		labels = append(labels, "back-to-back")
+	}
+	labels = append(labels, c.Name())
+	if c.Combo > 1 {
+		labels = append(labels, fmt.Sprintf("combo %d", c.Combo-1))
+	}
+	if c.Perfect {
+		labels = append(labels, "perfect clear")
+	}
+	return append(labels, fmt.Sprintf("+%d", c.Points))
 }
 
 func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
-	return 320, 240
+	return g.background.Layout()
 }
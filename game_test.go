@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/lcaballero/ebiten-01/sim"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -26,22 +27,25 @@ func (v vals) String(string) string {
 }
 
 func Test_NewGame(t *testing.T) {
+	// keep the settings and scores of the user out of the test
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
 	g := NewGame(NewGameOpts{vals: vals{}})
 	assert.NotNil(t, g.pieces)
-	assert.NotNil(t, g.board)
+	assert.NotNil(t, g.sim)
 	assert.NotNil(t, g.background)
-	assert.NotNil(t, g.current)
-	assert.NotNil(t, g.next)
-	assert.NotNil(t, g.rnd)
-	assert.NotNil(t, g.keys)
-	assert.False(t, g.paused)
+	assert.NotNil(t, g.input)
+	assert.Equal(t, DefaultOptions(), g.settings)
+	assert.Empty(t, g.scores.Top(sim.Marathon))
+	assert.False(t, g.sim.Paused())
+	assert.Equal(t, int64(2), g.sim.Config().Seed)
 
 	w, h := g.Layout(1, 1)
 	assert.Equal(t, 320, w)
 	assert.Equal(t, 240, h)
 
 	assert.Equal(t, 0, g.frames)
-	assert.Equal(t, time.Duration(0), g.elapsed)
+	assert.Equal(t, time.Second/60, g.tick)
 	assert.Equal(t, time.Duration(0), g.accum)
 	assert.Equal(t, time.Duration(0), g.seconds)
 }
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/lcaballero/ebiten-01/sim"
+)
+
+// PadButton is a button of the standard gamepad layout, named after
+// the buttons of an Xbox controller
+type PadButton ebiten.StandardGamepadButton
+
+const (
+	PadA     = PadButton(ebiten.StandardGamepadButtonRightBottom)
+	PadB     = PadButton(ebiten.StandardGamepadButtonRightRight)
+	PadX     = PadButton(ebiten.StandardGamepadButtonRightLeft)
+	PadY     = PadButton(ebiten.StandardGamepadButtonRightTop)
+	PadLB    = PadButton(ebiten.StandardGamepadButtonFrontTopLeft)
+	PadRB    = PadButton(ebiten.StandardGamepadButtonFrontTopRight)
+	PadLT    = PadButton(ebiten.StandardGamepadButtonFrontBottomLeft)
+	PadRT    = PadButton(ebiten.StandardGamepadButtonFrontBottomRight)
+	PadBack  = PadButton(ebiten.StandardGamepadButtonCenterLeft)
+	PadStart = PadButton(ebiten.StandardGamepadButtonCenterRight)
+	PadLS    = PadButton(ebiten.StandardGamepadButtonLeftStick)
+	PadRS    = PadButton(ebiten.StandardGamepadButtonRightStick)
+	PadUp    = PadButton(ebiten.StandardGamepadButtonLeftTop)
+	PadDown  = PadButton(ebiten.StandardGamepadButtonLeftBottom)
+	PadLeft  = PadButton(ebiten.StandardGamepadButtonLeftLeft)
+	PadRight = PadButton(ebiten.StandardGamepadButtonLeftRight)
+	PadHome  = PadButton(ebiten.StandardGamepadButtonCenterCenter)
+)
+
+func (b PadButton) String() string {
+	switch b {
+	case PadA:
+		return "a"
+	case PadB:
+		return "b"
+	case PadX:
+		return "x"
+	case PadY:
+		return "y"
+	case PadLB:
+		return "lb"
+	case PadRB:
+		return "rb"
+	case PadLT:
+		return "lt"
+	case PadRT:
+		return "rt"
+	case PadBack:
+		return "back"
+	case PadStart:
+		return "start"
+	case PadLS:
+		return "ls"
+	case PadRS:
+		return "rs"
+	case PadUp:
+		return "up"
+	case PadDown:
+		return "down"
+	case PadLeft:
+		return "left"
+	case PadRight:
+		return "right"
+	case PadHome:
+		return "home"
+	default:
+		return "unknown"
+	}
+}
+
+// padButtons lists every PadButton for parsing names
+var padButtons = []PadButton{
+	PadA, PadB, PadX, PadY, PadLB, PadRB, PadLT, PadRT, PadBack,
+	PadStart, PadLS, PadRS, PadUp, PadDown, PadLeft, PadRight, PadHome,
+}
+
+// ToPadButton parses the name of the PadButton, reporting false when
+// the name is unknown
+func ToPadButton(s string) (PadButton, bool) {
+	for _, b := range padButtons {
+		if b.String() == s {
+			return b, true
+		}
+	}
+	return 0, false
+}
+
+// MarshalText writes the PadButton by name
+func (b PadButton) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText reads the PadButton from its name
+func (b *PadButton) UnmarshalText(text []byte) error {
+	p, ok := ToPadButton(string(text))
+	if !ok {
+		return fmt.Errorf("unknown gamepad button %q", text)
+	}
+	*b = p
+	return nil
+}
+
+// PadBindings maps the name of each control to the gamepad buttons that
+// trigger it
+type PadBindings map[string][]PadButton
+
+func DefaultPadBindings() PadBindings {
+	return PadBindings{
+		sim.MoveLeft.String():  {PadLeft},
+		sim.MoveRight.String(): {PadRight},
+		sim.SoftDrop.String():  {PadDown},
+		sim.HardDrop.String():  {PadUp},
+		sim.RotateCW.String():  {PadA},
+		sim.RotateCCW.String(): {PadB},
+		sim.Rotate180.String(): {PadX},
+		sim.Hold.String():      {PadLB, PadRB},
+		sim.Pause.String():     {PadStart},
+		sim.Restart.String():   {PadBack},
+		ToggleGhost.String():   {PadY},
+	}
+}
+
+// check makes sure no button triggers more than one control
+func (p PadBindings) check() error {
+	bound := map[PadButton]string{}
+	for _, name := range controlNames {
+		for _, b := range p[name] {
+			if other, ok := bound[b]; ok {
+				return fmt.Errorf("gamepad button %s is bound to both %s and %s", b, other, name)
+			}
+			bound[b] = name
+		}
+	}
+	return nil
+}
+
+// unbind removes the buttons from every control, so that they can be
+// bound to another
+func (p PadBindings) unbind(buttons []PadButton) {
+	for name, bound := range p {
+		kept := []PadButton{}
+		for _, b := range bound {
+			if !hasButton(buttons, b) {
+				kept = append(kept, b)
+			}
+		}
+		p[name] = kept
+	}
+}
+
+func hasButton(buttons []PadButton, b PadButton) bool {
+	for _, button := range buttons {
+		if button == b {
+			return true
+		}
+	}
+	return false
+}
+
+// Gamepads reads the state of the connected gamepads, so that a fake
+// can stand in for the hardware
+type Gamepads interface {
+	// IDs reports the gamepads connected with the standard layout
+	IDs() []ebiten.GamepadID
+	Name(id ebiten.GamepadID) string
+	Pressed(id ebiten.GamepadID, b PadButton) bool
+	// Axis reports the position of a stick from -1 to 1, with down and
+	// right positive
+	Axis(id ebiten.GamepadID, a ebiten.StandardGamepadAxis) float64
+}
+
+// ebitenGamepads reads the gamepads through ebiten
+type ebitenGamepads struct{}
+
+func (ebitenGamepads) IDs() []ebiten.GamepadID {
+	ids := []ebiten.GamepadID{}
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if ebiten.IsStandardGamepadLayoutAvailable(id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func (ebitenGamepads) Name(id ebiten.GamepadID) string {
+	return ebiten.GamepadName(id)
+}
+
+func (ebitenGamepads) Pressed(id ebiten.GamepadID, b PadButton) bool {
+	return ebiten.IsStandardGamepadButtonPressed(id, ebiten.StandardGamepadButton(b))
+}
+
+func (ebitenGamepads) Axis(id ebiten.GamepadID, a ebiten.StandardGamepadAxis) float64 {
+	return ebiten.StandardGamepadAxisValue(id, a)
+}
+
+// GamepadHandler reads every connected gamepad each tick into actions
+// for the sim and commands for the game, the same as the KBHandler.
+// The left stick moves and soft drops like the D-pad once it is pushed
+// past the deadzone.
+type GamepadHandler struct {
+	pads      Gamepads
+	buttons   PadBindings
+	deadzone  float64
+	connected map[ebiten.GamepadID]string // by id, the name of each gamepad
+	last      map[string]bool             // controls active on the last tick
+}
+
+func NewGamepadHandler(pads Gamepads, buttons PadBindings, deadzone float64) *GamepadHandler {
+	return &GamepadHandler{
+		pads:      pads,
+		buttons:   buttons,
+		deadzone:  deadzone,
+		connected: map[ebiten.GamepadID]string{},
+		last:      map[string]bool{},
+	}
+}
+
+// plug notes the gamepads connected or disconnected since the last
+// tick, so that a gamepad can be swapped at any time
+func (h *GamepadHandler) plug(ids []ebiten.GamepadID) {
+	seen := map[ebiten.GamepadID]bool{}
+	for _, id := range ids {
+		seen[id] = true
+		if _, ok := h.connected[id]; !ok {
+			h.connected[id] = h.pads.Name(id)
+			log.Printf("gamepad connected: %d %s", id, h.connected[id])
+		}
+	}
+	for id, name := range h.connected {
+		if !seen[id] {
+			delete(h.connected, id)
+			log.Printf("gamepad disconnected: %d %s", id, name)
+		}
+	}
+}
+
+// stick reports the control the left stick is pushed toward, left,
+// right or down, once it is past the deadzone
+func (h *GamepadHandler) stick(id ebiten.GamepadID) sim.Action {
+	x := h.pads.Axis(id, ebiten.StandardGamepadAxisLeftStickHorizontal)
+	y := h.pads.Axis(id, ebiten.StandardGamepadAxisLeftStickVertical)
+	ax, ay := x, y
+	if ax < 0 {
+		ax = -ax
+	}
+	if ay < 0 {
+		ay = -ay
+	}
+	switch {
+	case ax > h.deadzone && ax >= ay && x < 0:
+		return sim.MoveLeft
+	case ax > h.deadzone && ax >= ay:
+		return sim.MoveRight
+	case ay > h.deadzone && y > 0:
+		return sim.SoftDrop
+	default:
+		return 0
+	}
+}
+
+// active reports the controls held on any of the gamepads
+func (h *GamepadHandler) active() map[string]bool {
+	ids := h.pads.IDs()
+	h.plug(ids)
+	on := map[string]bool{}
+	for _, id := range ids {
+		for name, buttons := range h.buttons {
+			for _, b := range buttons {
+				if h.pads.Pressed(id, b) {
+					on[name] = true
+				}
+			}
+		}
+		if a := h.stick(id); a != 0 {
+			on[a.String()] = true
+		}
+	}
+	return on
+}
+
+// Pressed pushes the actions of the controls pressed since the last
+// tick onto the queue and reports the commands that were pressed
+func (h *GamepadHandler) Pressed(q *sim.ActionQueue) []Command {
+	on := h.active()
+	cmds := []Command{}
+	for name := range on {
+		if h.last[name] {
+			continue
+		}
+		if a := sim.ToAction(name); a != 0 && !held(a) {
+			q.Push(a)
+		}
+		if c := ToCommand(name); c != 0 {
+			cmds = append(cmds, c)
+		}
+	}
+	h.last = on
+	sort.Slice(cmds, func(i, j int) bool { return cmds[i] < cmds[j] })
+	return cmds
+}
+
+// Held reports the moves and soft drop held on any of the gamepads
+func (h *GamepadHandler) Held() []sim.Action {
+	return heldActions(h.last)
+}
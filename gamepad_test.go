@@ -0,0 +1,171 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/lcaballero/ebiten-01/sim"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePad is the state of a gamepad held in a test
+type fakePad struct {
+	buttons map[PadButton]bool
+	axes    map[ebiten.StandardGamepadAxis]float64
+}
+
+// fakeGamepads stands in for the gamepads connected to the machine
+type fakeGamepads map[ebiten.GamepadID]*fakePad
+
+func (f fakeGamepads) plug(id ebiten.GamepadID) *fakePad {
+	p := &fakePad{
+		buttons: map[PadButton]bool{},
+		axes:    map[ebiten.StandardGamepadAxis]float64{},
+	}
+	f[id] = p
+	return p
+}
+
+func (f fakeGamepads) IDs() []ebiten.GamepadID {
+	ids := []ebiten.GamepadID{}
+	for id := range f {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func (f fakeGamepads) Name(id ebiten.GamepadID) string {
+	return "fake"
+}
+
+func (f fakeGamepads) Pressed(id ebiten.GamepadID, b PadButton) bool {
+	return f[id].buttons[b]
+}
+
+func (f fakeGamepads) Axis(id ebiten.GamepadID, a ebiten.StandardGamepadAxis) float64 {
+	return f[id].axes[a]
+}
+
+func Test_PadButton_Names(t *testing.T) {
+	for _, b := range padButtons {
+		p, ok := ToPadButton(b.String())
+		assert.True(t, ok, b.String())
+		assert.Equal(t, b, p)
+	}
+	_, ok := ToPadButton("z")
+	assert.False(t, ok)
+}
+
+func Test_GamepadHandler_Pressed(t *testing.T) {
+	pads := fakeGamepads{}
+	h := NewGamepadHandler(pads, DefaultPadBindings(), DefaultDeadzone)
+	pad := pads.plug(0)
+
+	pad.buttons[PadA] = true
+	pad.buttons[PadY] = true
+	q := sim.NewActionQueue()
+	assert.Equal(t, []Command{ToggleGhost}, h.Pressed(q))
+	assert.Equal(t, []sim.Action{sim.RotateCW}, q.Drain())
+
+	assert.Empty(t, h.Pressed(q), "held buttons are pressed once")
+	assert.Empty(t, q.Drain())
+
+	pad.buttons[PadA] = false
+	h.Pressed(q)
+	pad.buttons[PadA] = true
+	h.Pressed(q)
+	assert.Equal(t, []sim.Action{sim.RotateCW}, q.Drain())
+}
+
+func Test_GamepadHandler_Held(t *testing.T) {
+	table := []struct {
+		name    string
+		buttons []PadButton
+		x, y    float64
+		held    []sim.Action
+	}{
+		{name: "nothing", held: []sim.Action{}},
+		{name: "d-pad left", buttons: []PadButton{PadLeft}, held: []sim.Action{sim.MoveLeft}},
+		{name: "d-pad down", buttons: []PadButton{PadDown}, held: []sim.Action{sim.SoftDrop}},
+		{name: "stick left", x: -0.8, held: []sim.Action{sim.MoveLeft}},
+		{name: "stick right", x: 0.8, held: []sim.Action{sim.MoveRight}},
+		{name: "stick down", y: 0.8, held: []sim.Action{sim.SoftDrop}},
+		{name: "stick up is ignored", y: -0.8, held: []sim.Action{}},
+		{name: "stick in the deadzone", x: -0.2, y: 0.2, held: []sim.Action{}},
+		{name: "stick mostly right", x: 0.7, y: 0.5, held: []sim.Action{sim.MoveRight}},
+		{name: "stick mostly down", x: -0.5, y: 0.7, held: []sim.Action{sim.SoftDrop}},
+	}
+	for _, row := range table {
+		t.Run(row.name, func(t *testing.T) {
+			pads := fakeGamepads{}
+			pad := pads.plug(3)
+			for _, b := range row.buttons {
+				pad.buttons[b] = true
+			}
+			pad.axes[ebiten.StandardGamepadAxisLeftStickHorizontal] = row.x
+			pad.axes[ebiten.StandardGamepadAxisLeftStickVertical] = row.y
+			h := NewGamepadHandler(pads, DefaultPadBindings(), DefaultDeadzone)
+			q := sim.NewActionQueue()
+			h.Pressed(q)
+			assert.Equal(t, row.held, h.Held())
+			assert.Empty(t, q.Drain(), "moves repeat while held rather than on a press")
+		})
+	}
+}
+
+func Test_GamepadHandler_HotPlug(t *testing.T) {
+	pads := fakeGamepads{}
+	h := NewGamepadHandler(pads, DefaultPadBindings(), DefaultDeadzone)
+	q := sim.NewActionQueue()
+	h.Pressed(q)
+	assert.Empty(t, h.connected)
+
+	pads.plug(1).buttons[PadLeft] = true
+	h.Pressed(q)
+	assert.Equal(t, map[ebiten.GamepadID]string{1: "fake"}, h.connected)
+	assert.Equal(t, []sim.Action{sim.MoveLeft}, h.Held())
+
+	delete(pads, 1)
+	h.Pressed(q)
+	assert.Empty(t, h.connected)
+	assert.Empty(t, h.Held(), "a gamepad unplugged lets go of its buttons")
+
+	pads.plug(2).buttons[PadLB] = true
+	h.Pressed(q)
+	assert.Equal(t, []sim.Action{sim.Hold}, q.Drain())
+}
+
+// fakeInput reports the commands and held actions it is given
+type fakeInput struct {
+	cmds []Command
+	held []sim.Action
+}
+
+func (f *fakeInput) Pressed(q *sim.ActionQueue) []Command {
+	return f.cmds
+}
+
+func (f *fakeInput) Held() []sim.Action {
+	return f.held
+}
+
+func Test_Inputs(t *testing.T) {
+	keys := &fakeInput{cmds: []Command{ToggleGhost}}
+	pads := fakeGamepads{}
+	pad := pads.plug(0)
+	pad.buttons[PadY] = true
+	pad.axes[ebiten.StandardGamepadAxisLeftStickHorizontal] = 1
+	shift := sim.NewShifter(sim.DefaultRepeatRules(), 10)
+	in := NewInputs(shift, nil, keys, NewGamepadHandler(pads, DefaultPadBindings(), DefaultDeadzone))
+
+	q := sim.NewActionQueue()
+	assert.Equal(t, []Command{ToggleGhost}, in.Pressed(q), "commands from both devices are seen once")
+	assert.Equal(t, []sim.Action{sim.MoveRight}, in.Shift(time.Millisecond))
+	assert.Empty(t, in.Drop(time.Millisecond))
+
+	keys.held = []sim.Action{sim.SoftDrop}
+	in.Pressed(q)
+	assert.Empty(t, in.Shift(time.Millisecond), "still waiting on DAS")
+	assert.Equal(t, []sim.Action{sim.SoftDrop}, in.Drop(time.Millisecond))
+}
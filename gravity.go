@@ -0,0 +1,29 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/lcaballero/ebiten-01/sim"
+)
+
+// MustLoadGravity finds the built in gravity curve by name, or reads a
+// custom curve from a .yaml file
+func MustLoadGravity(name string) sim.GravityCurve {
+	switch filepath.Ext(name) {
+	case ".yaml", ".yml":
+	default:
+		return sim.NewGravityCurve(sim.ToGravityKind(name))
+	}
+	log.Printf("loading gravity: %s", name)
+	bin, err := os.ReadFile(name)
+	if err != nil {
+		panic(err)
+	}
+	curve, err := sim.ParseGravityCurve(bin)
+	if err != nil {
+		panic(err)
+	}
+	return curve
+}
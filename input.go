@@ -0,0 +1,112 @@
+package main
+
+import (
+	"sort"
+	"time"
+
+	"github.com/lcaballero/ebiten-01/sim"
+)
+
+// Input is a device, such as the keyboard or the gamepads, read each
+// tick for the controls pressed and held
+type Input interface {
+	// Pressed pushes the actions pressed since the last tick onto the
+	// queue and reports the commands pressed
+	Pressed(q *sim.ActionQueue) []Command
+	// Held reports the moves and soft drop held down during the tick,
+	// read after Pressed
+	Held() []sim.Action
+}
+
+// held reports if the action repeats while it is held down rather than
+// being applied once for each press
+func held(a sim.Action) bool {
+	return a == sim.MoveLeft || a == sim.MoveRight || a == sim.SoftDrop
+}
+
+// heldActions reports the actions that repeat of the active controls,
+// in a fixed order
+func heldActions(on map[string]bool) []sim.Action {
+	actions := []sim.Action{}
+	for _, a := range []sim.Action{sim.MoveLeft, sim.MoveRight, sim.SoftDrop} {
+		if on[a.String()] {
+			actions = append(actions, a)
+		}
+	}
+	return actions
+}
+
+// Inputs reads all of the devices as one, so that a move held on any
+// of them repeats by the same rules
+type Inputs struct {
+	devices []Input
+	shift   *sim.Shifter // repeats left and right while held
+	drop    *sim.Shifter // repeats moving down, or nil to speed up gravity
+	held    []sim.Action // held during the tick
+}
+
+// NewInputs reads the devices, repeating left and right with the
+// shifter and soft drop with the drop shifter when one is given
+func NewInputs(shift, drop *sim.Shifter, devices ...Input) *Inputs {
+	return &Inputs{
+		devices: devices,
+		shift:   shift,
+		drop:    drop,
+	}
+}
+
+// Pressed pushes the actions pressed on any device since the last tick
+// onto the queue and reports the commands pressed, once each
+func (in *Inputs) Pressed(q *sim.ActionQueue) []Command {
+	seen := map[Command]bool{}
+	cmds := []Command{}
+	in.held = nil
+	for _, d := range in.devices {
+		for _, c := range d.Pressed(q) {
+			if !seen[c] {
+				seen[c] = true
+				cmds = append(cmds, c)
+			}
+		}
+		in.held = append(in.held, d.Held()...)
+	}
+	sort.Slice(cmds, func(i, j int) bool { return cmds[i] < cmds[j] })
+	return cmds
+}
+
+func (in *Inputs) holds(a sim.Action) bool {
+	for _, h := range in.held {
+		if h == a {
+			return true
+		}
+	}
+	return false
+}
+
+// Shift reports the moves left and right for the tick from the
+// controls being held down
+func (in *Inputs) Shift(elapsed time.Duration) []sim.Action {
+	moves := []sim.Action{}
+	for _, a := range []sim.Action{sim.MoveLeft, sim.MoveRight} {
+		if in.holds(a) {
+			moves = append(moves, a)
+		}
+	}
+	return in.shift.Update(elapsed, moves...)
+}
+
+// Drop reports the soft drop for the tick while it is held down, as
+// faster gravity or as repeated moves down
+func (in *Inputs) Drop(elapsed time.Duration) []sim.Action {
+	soft := in.holds(sim.SoftDrop)
+	switch {
+	case in.drop != nil && soft:
+		return in.drop.Update(elapsed, sim.MoveDown)
+	case in.drop != nil:
+		return in.drop.Update(elapsed)
+	case soft:
+		return []sim.Action{sim.SoftDrop}
+	default:
+		return nil
+	}
+}
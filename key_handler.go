@@ -1,90 +1,106 @@
 package main
 
 import (
-	"time"
+	"sort"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/lcaballero/ebiten-01/sim"
 )
 
-const keyResolution = time.Second / 10
+// Command is an input handled by the game window rather than by the
+// simulation
+type Command int
 
-type KBHandler struct {
-	handler    chan ebiten.Key
-	right      *KeyHandler
-	left       *KeyHandler
-	down       *KeyHandler
-	rotate     *KeyHandler
-	resetPeice *KeyHandler
-	quit       *KeyHandler
-	pause      *KeyHandler
-	playJab    *KeyHandler
-	restart    *KeyHandler
-}
+const (
+	Quit        Command = 1
+	ToggleGhost Command = 2
+	PlayJab     Command = 3
+)
 
-func NewKBHandler() *KBHandler {
-	out := make(chan ebiten.Key, 1)
-	res := keyResolution
-	return &KBHandler{
-		handler:    out,
-		left:       NewKeyHandler(ebiten.KeyJ, res, out),
-		right:      NewKeyHandler(ebiten.KeyL, res, out),
-		down:       NewKeyHandler(ebiten.KeyK, res, out),
-		rotate:     NewKeyHandler(ebiten.KeySpace, res, out),
-		resetPeice: NewKeyHandler(ebiten.KeyR, res, out),
-		quit:       NewKeyHandler(ebiten.KeyQ, res, out),
-		pause:      NewKeyHandler(ebiten.KeyP, res, out),
-		playJab:    NewKeyHandler(ebiten.Key1, res, out),
-		restart:    NewKeyHandler(ebiten.Key0, res, out),
+func (c Command) String() string {
+	switch c {
+	case Quit:
+		return "quit"
+	case ToggleGhost:
+		return "toggle-ghost"
+	case PlayJab:
+		return "play-jab"
+	default:
+		return "unknown"
 	}
 }
 
-func (h *KBHandler) Update(paused bool, elapsed time.Duration) {
-	if !paused {
-		h.right.Update(elapsed)
-		h.left.Update(elapsed)
-		h.down.Update(elapsed)
-		h.rotate.Update(elapsed)
-		h.resetPeice.Update(elapsed)
+// ToCommand parses the name of the Command, reporting 0 when the name
+// is unknown
+func ToCommand(s string) Command {
+	for _, c := range []Command{Quit, ToggleGhost, PlayJab} {
+		if c.String() == s {
+			return c
+		}
 	}
-	h.quit.Update(elapsed)
-	h.pause.Update(elapsed)
-	h.playJab.Update(elapsed)
-	h.restart.Update(elapsed)
+	return 0
 }
 
-type KeyHandler struct {
-	key        ebiten.Key
-	resolution time.Duration
-	last       time.Duration
-	accum      time.Duration
-	out        chan ebiten.Key
+// KBHandler reads the keyboard each tick into actions for the sim and
+// commands for the game, so that every key pressed is seen
+type KBHandler struct {
+	actions  map[ebiten.Key]sim.Action // applied once for each press
+	commands map[ebiten.Key]Command
+	held     map[string][]ebiten.Key // repeated while held down
 }
 
-func NewKeyHandler(key ebiten.Key, res time.Duration, out chan ebiten.Key) *KeyHandler {
-	return &KeyHandler{
-		key:        key,
-		resolution: res,
-		out:        out,
+// NewKBHandler reads the keys of the bindings
+func NewKBHandler(b Bindings) *KBHandler {
+	h := &KBHandler{
+		actions:  map[ebiten.Key]sim.Action{},
+		commands: map[ebiten.Key]Command{},
+		held:     map[string][]ebiten.Key{},
+	}
+	for name, keys := range b {
+		a := sim.ToAction(name)
+		c := ToCommand(name)
+		for _, k := range keys {
+			switch {
+			case held(a):
+				h.held[name] = append(h.held[name], k)
+			case a != 0:
+				h.actions[k] = a
+			case c != 0:
+				h.commands[k] = c
+			}
+		}
 	}
+	return h
 }
 
-func (k *KeyHandler) pushKey(out chan ebiten.Key, key ebiten.Key) {
-	isPressed := inpututil.IsKeyJustPressed(key)
-	if !isPressed {
-		return
+// Pressed pushes the actions of the keys pressed since the last tick
+// onto the queue and reports the commands that were pressed
+func (h *KBHandler) Pressed(q *sim.ActionQueue) []Command {
+	for key, a := range h.actions {
+		if inpututil.IsKeyJustPressed(key) {
+			q.Push(a)
+		}
 	}
-	select {
-	case out <- key:
-		k.last = k.accum
-	default:
+	cmds := []Command{}
+	for key, c := range h.commands {
+		if inpututil.IsKeyJustPressed(key) {
+			cmds = append(cmds, c)
+		}
 	}
+	sort.Slice(cmds, func(i, j int) bool { return cmds[i] < cmds[j] })
+	return cmds
 }
 
-func (k *KeyHandler) Update(elapsed time.Duration) {
-	k.accum += elapsed
-	ds := k.accum - k.last
-	if ds > k.resolution {
-		k.pushKey(k.out, k.key)
+// Held reports the moves and soft drop whose keys are held down
+func (h *KBHandler) Held() []sim.Action {
+	on := map[string]bool{}
+	for name, keys := range h.held {
+		for _, k := range keys {
+			if ebiten.IsKeyPressed(k) {
+				on[name] = true
+			}
+		}
 	}
+	return heldActions(on)
 }
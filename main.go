@@ -5,13 +5,17 @@ import (
 	"os"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/lcaballero/ebiten-01/sim"
 )
 
 //go:generate go run ./scripts/cli/main.go
 
 func main() {
 	procs := Procs{
-		NewGame: StartGame,
+		NewGame:  StartGame,
+		Replay:   StartReplay,
+		Controls: StartControls,
+		Scores:   StartScores,
 	}
 	err := NewApp(procs).Run(os.Args)
 	if err != nil {
@@ -21,8 +25,62 @@ func main() {
 
 func StartGame(vals Vals) error {
 	game := NewGame(NewGameOpts{vals})
-	ebiten.SetWindowSize(640, 480)
-	ebiten.SetWindowTitle("Tetris")
-	err := ebiten.RunGame(game)
-	return err
+	err := run(game, "Tetris")
+	if err != nil {
+		return err
+	}
+	return game.saveRecording()
+}
+
+func StartReplay(vals Vals) error {
+	opts := ReplayOpts{vals}
+	game := NewReplayGame(opts, MustLoadReplay(opts.File()))
+	return run(game, "Tetris - replay")
+}
+
+// StartControls prints the active key and gamepad bindings, from the
+// flag or else the settings
+func StartControls(vals Vals) error {
+	opts := ControlsOpts{vals}
+	settings, _ := loadSettings()
+	controls := settings.Controls
+	if opts.HasControls() {
+		controls = opts.Controls()
+	}
+	return MustLoadControls(controls).Write(os.Stdout)
+}
+
+// StartScores prints the high scores, first keeping only the best of
+// each mode when asked to prune them
+func StartScores(vals Vals) error {
+	opts := ScoresOpts{vals}
+	path, err := ScoresPath()
+	if err != nil {
+		return err
+	}
+	h, err := LoadHighScores(path)
+	if err != nil {
+		return err
+	}
+	var mode sim.Mode
+	if opts.HasMode() {
+		mode = sim.ToMode(opts.Mode())
+	}
+	if opts.HasPrune() {
+		removed := h.Prune(clamp(opts.Prune(), 0, sim.MaxScores), mode)
+		removeReplays(removed)
+		err = SaveHighScores(path, h)
+		if err != nil {
+			return err
+		}
+		log.Printf("pruned %d scores: %s", len(removed), path)
+	}
+	return WriteScores(os.Stdout, h, mode)
+}
+
+func run(game *Game, title string) error {
+	w, h := game.Layout(0, 0)
+	ebiten.SetWindowSize(w*2, h*2)
+	ebiten.SetWindowTitle(title)
+	return ebiten.RunGame(game)
 }
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/lcaballero/ebiten-01/shapes"
+	"golang.org/x/image/font/basicfont"
+)
+
+// MenuKey is an input for moving through the menus of the scenes
+type MenuKey int
+
+const (
+	MenuUp     MenuKey = 1
+	MenuDown   MenuKey = 2
+	MenuLeft   MenuKey = 3
+	MenuRight  MenuKey = 4
+	MenuSelect MenuKey = 5
+	MenuBack   MenuKey = 6
+)
+
+func (k MenuKey) String() string {
+	switch k {
+	case MenuUp:
+		return "up"
+	case MenuDown:
+		return "down"
+	case MenuLeft:
+		return "left"
+	case MenuRight:
+		return "right"
+	case MenuSelect:
+		return "select"
+	case MenuBack:
+		return "back"
+	default:
+		return "unknown"
+	}
+}
+
+// menuKeys move through the menus from the keyboard
+var menuKeys = map[ebiten.Key]MenuKey{
+	ebiten.KeyArrowUp:    MenuUp,
+	ebiten.KeyW:          MenuUp,
+	ebiten.KeyArrowDown:  MenuDown,
+	ebiten.KeyS:          MenuDown,
+	ebiten.KeyArrowLeft:  MenuLeft,
+	ebiten.KeyA:          MenuLeft,
+	ebiten.KeyArrowRight: MenuRight,
+	ebiten.KeyD:          MenuRight,
+	ebiten.KeyEnter:      MenuSelect,
+	ebiten.KeySpace:      MenuSelect,
+	ebiten.KeyEscape:     MenuBack,
+	ebiten.KeyBackspace:  MenuBack,
+}
+
+// menuButtons move through the menus from a gamepad
+var menuButtons = map[PadButton]MenuKey{
+	PadUp:    MenuUp,
+	PadDown:  MenuDown,
+	PadLeft:  MenuLeft,
+	PadRight: MenuRight,
+	PadA:     MenuSelect,
+	PadStart: MenuSelect,
+	PadB:     MenuBack,
+	PadBack:  MenuBack,
+}
+
+// MenuReader reads the keyboard and gamepads each tick for the menu
+// keys pressed since the last tick
+type MenuReader struct {
+	pads Gamepads
+	last map[MenuKey]bool // held on the last tick
+}
+
+func NewMenuReader(pads Gamepads) *MenuReader {
+	return &MenuReader{
+		pads: pads,
+		last: map[MenuKey]bool{},
+	}
+}
+
+// held reports the menu keys held down on any device
+func (r *MenuReader) held() map[MenuKey]bool {
+	on := map[MenuKey]bool{}
+	for key, k := range menuKeys {
+		if ebiten.IsKeyPressed(key) {
+			on[k] = true
+		}
+	}
+	for _, id := range r.pads.IDs() {
+		for b, k := range menuButtons {
+			if r.pads.Pressed(id, b) {
+				on[k] = true
+			}
+		}
+	}
+	return on
+}
+
+// Pressed reports the menu keys pressed since the last tick, in order
+func (r *MenuReader) Pressed() []MenuKey {
+	return r.update(r.held())
+}
+
+func (r *MenuReader) update(on map[MenuKey]bool) []MenuKey {
+	pressed := []MenuKey{}
+	for k := range on {
+		if !r.last[k] {
+			pressed = append(pressed, k)
+		}
+	}
+	r.last = on
+	sort.Slice(pressed, func(i, j int) bool { return pressed[i] < pressed[j] })
+	return pressed
+}
+
+// Skip forgets the keys held down now, so that a press that switched
+// scenes is not seen again by the next one
+func (r *MenuReader) Skip() {
+	r.last = r.held()
+}
+
+// MenuItem is a line of a Menu, where options show a Value that can be
+// changed from side to side
+type MenuItem struct {
+	Label  string
+	Value  func() string  // shown after the label when set
+	Select func()         // called when the item is selected
+	Change func(step int) // called with -1 or 1 moving left or right
+}
+
+// Menu is a list of items with a cursor on the one to select
+type Menu struct {
+	Title  string
+	Items  []MenuItem
+	cursor int
+}
+
+func NewMenu(title string, items ...MenuItem) *Menu {
+	return &Menu{
+		Title: title,
+		Items: items,
+	}
+}
+
+// Handle moves the cursor or calls on the item under it for the key,
+// reporting false for the keys left to the scene, such as MenuBack
+func (m *Menu) Handle(k MenuKey) bool {
+	item := m.Items[m.cursor]
+	switch k {
+	case MenuUp:
+		m.cursor = (m.cursor + len(m.Items) - 1) % len(m.Items)
+	case MenuDown:
+		m.cursor = (m.cursor + 1) % len(m.Items)
+	case MenuLeft, MenuRight:
+		if item.Change == nil {
+			return false
+		}
+		step := 1
+		if k == MenuLeft {
+			step = -1
+		}
+		item.Change(step)
+	case MenuSelect:
+		switch {
+		case item.Select != nil:
+			item.Select()
+		case item.Change != nil:
+			item.Change(1)
+		default:
+			return false
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// Lines describes each item, marking the one under the cursor
+func (m *Menu) Lines() []string {
+	lines := []string{}
+	for i, item := range m.Items {
+		mark := "  "
+		if i == m.cursor {
+			mark = "> "
+		}
+		line := mark + item.Label
+		if item.Value != nil {
+			line = fmt.Sprintf("%s%-11s %s", mark, item.Label, item.Value())
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// onOff names the value of a setting that is on or off
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
+
+// lineHeight is the pixels between lines of text in a panel
+const lineHeight = 14
+
+func drawText(screen *ebiten.Image, s string, pos shapes.Vec) {
+	x, y := pos.IntComponents()
+	text.Draw(screen, s, basicfont.Face7x13, x, y, color.White)
+}
+
+// dim darkens the scene drawn underneath an overlay
+func dim(screen *ebiten.Image) {
+	w, h := screen.Bounds().Dx(), screen.Bounds().Dy()
+	vector.DrawFilledRect(screen, 0, 0, float32(w), float32(h), color.RGBA{A: 128}, false)
+}
+
+// drawPanel draws the title and lines in a box centered on the area
+func drawPanel(screen *ebiten.Image, area shapes.Rect, title string, lines []string) {
+	h := float64(len(lines)+2)*lineHeight + 8
+	panel := shapes.NewRectAt(area.X()+20, area.Center().Y()-h/2, area.W()-40, h)
+	x, y, w, ph := panel.Components()
+	vector.DrawFilledRect(screen, float32(x), float32(y), float32(w), float32(ph), color.RGBA{A: 224}, false)
+	drawText(screen, title, panel.Pos.Add(shapes.Vec{10, lineHeight}))
+	for i, line := range lines {
+		drawText(screen, line, panel.Pos.Add(shapes.Vec{10, float64(i+3) * lineHeight}))
+	}
+}
+
+// drawMenu draws the menu in a panel, below any lines describing the
+// scene
+func drawMenu(screen *ebiten.Image, area shapes.Rect, m *Menu, about ...string) {
+	lines := append([]string{}, about...)
+	if len(about) > 0 {
+		lines = append(lines, "")
+	}
+	drawPanel(screen, area, m.Title, append(lines, m.Lines()...))
+}
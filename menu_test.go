@@ -0,0 +1,68 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lcaballero/ebiten-01/sim"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Menu_Handle(t *testing.T) {
+	selected := ""
+	level := 1
+	m := NewMenu("Test",
+		MenuItem{Label: "Play", Select: func() { selected = "play" }},
+		MenuItem{
+			Label:  "Level",
+			Value:  func() string { return "x" },
+			Change: func(step int) { level += step },
+		},
+		MenuItem{Label: "Quit", Select: func() { selected = "quit" }},
+	)
+	assert.Equal(t, []string{"> Play", "  Level       x", "  Quit"}, m.Lines())
+
+	assert.True(t, m.Handle(MenuUp), "wraps to the bottom")
+	assert.True(t, m.Handle(MenuSelect))
+	assert.Equal(t, "quit", selected)
+
+	assert.True(t, m.Handle(MenuDown), "wraps to the top")
+	assert.False(t, m.Handle(MenuRight), "nothing to change")
+	assert.True(t, m.Handle(MenuSelect))
+	assert.Equal(t, "play", selected)
+
+	m.Handle(MenuDown)
+	assert.True(t, m.Handle(MenuRight))
+	assert.True(t, m.Handle(MenuRight))
+	assert.True(t, m.Handle(MenuLeft))
+	assert.True(t, m.Handle(MenuSelect))
+	assert.Equal(t, 3, level)
+
+	assert.False(t, m.Handle(MenuBack), "left to the scene")
+}
+
+func Test_MenuReader(t *testing.T) {
+	r := NewMenuReader(fakeGamepads{})
+	assert.Equal(t, []MenuKey{MenuDown, MenuSelect}, r.update(map[MenuKey]bool{MenuSelect: true, MenuDown: true}))
+	assert.Empty(t, r.update(map[MenuKey]bool{MenuSelect: true}), "held keys are pressed once")
+	assert.Empty(t, r.update(map[MenuKey]bool{}))
+	assert.Equal(t, []MenuKey{MenuSelect}, r.update(map[MenuKey]bool{MenuSelect: true}))
+}
+
+func Test_Results(t *testing.T) {
+	state := sim.State{
+		Mode:  sim.Sprint,
+		Time:  83*time.Second + 456*time.Millisecond,
+		Score: sim.ScoreBoard{Score: 1200, Lines: 40, Level: 5},
+	}
+	assert.Equal(t, []string{
+		"Sprint 40 lines",
+		"Score 1200     Lines 40",
+		"Level 5        Time  1:23.46",
+	}, results(state))
+	assert.Equal(t, "Ultra 2 minutes", modeLabel(sim.Ultra))
+	assert.Equal(t, "Marathon", modeLabel(0))
+	assert.Equal(t, "0:05.00", formatTime(5*time.Second))
+	assert.Equal(t, "1 left", status(sim.State{Mode: sim.Sprint, Score: sim.ScoreBoard{Lines: 39}}))
+	assert.Equal(t, "1:30.00", status(sim.State{Mode: sim.Ultra, Time: 30 * time.Second}))
+}
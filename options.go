@@ -0,0 +1,218 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lcaballero/ebiten-01/sim"
+	"gopkg.in/yaml.v3"
+)
+
+// Options are the settings a player can change from the options
+// screen, saved between runs in the settings file
+type Options struct {
+	DAS            int    `yaml:"das"` // milliseconds
+	ARR            int    `yaml:"arr"` // milliseconds
+	SoftDropFactor int    `yaml:"soft-drop-factor"`
+	Volume         int    `yaml:"volume"` // percent of the master bus
+	MusicVolume    int    `yaml:"music-volume"`
+	SFXVolume      int    `yaml:"sfx-volume"`
+	Ghost          bool   `yaml:"ghost"`
+	Grid           bool   `yaml:"grid"`
+	Skin           int    `yaml:"skin"` // of every block, from 1, or 0 for the skin of each piece
+	Previews       int    `yaml:"previews"`
+	Rotate180      bool   `yaml:"rotate-180"`
+	ShowFPS        bool   `yaml:"show-fps"`
+	Controls       string `yaml:"controls"` // a preset or a controls file
+}
+
+const (
+	MaxRepeat         = 500 // milliseconds of DAS or ARR
+	MaxSoftDropFactor = 40
+	MaxVolume         = 100
+)
+
+func DefaultOptions() Options {
+	rules := sim.DefaultRepeatRules()
+	return Options{
+		DAS:            int(rules.DAS / time.Millisecond),
+		ARR:            int(rules.ARR / time.Millisecond),
+		SoftDropFactor: 20,
+		Volume:         50,
+		MusicVolume:    MaxVolume,
+		SFXVolume:      MaxVolume,
+		Previews:       5,
+		Controls:       DefaultPreset,
+	}
+}
+
+// clamp keeps each option within its range
+func (o Options) clamp() Options {
+	o.DAS = clamp(o.DAS, 0, MaxRepeat)
+	o.ARR = clamp(o.ARR, 0, MaxRepeat)
+	o.SoftDropFactor = clamp(o.SoftDropFactor, 1, MaxSoftDropFactor)
+	o.Volume = clamp(o.Volume, 0, MaxVolume)
+	o.MusicVolume = clamp(o.MusicVolume, 0, MaxVolume)
+	o.SFXVolume = clamp(o.SFXVolume, 0, MaxVolume)
+	o.Skin = clamp(o.Skin, 0, skins)
+	o.Previews = sim.ClampPreviews(o.Previews)
+	if o.Controls == "" {
+		o.Controls = DefaultPreset
+	}
+	return o
+}
+
+func clamp(n, lo, hi int) int {
+	if n < lo {
+		return lo
+	}
+	if n > hi {
+		return hi
+	}
+	return n
+}
+
+// withFlags overrides the options with the flags that were given
+func (o Options) withFlags(opts NewGameOpts) Options {
+	if opts.HasDas() {
+		o.DAS = int(MustParseRepeat(opts.Das()) / time.Millisecond)
+	}
+	if opts.HasArr() {
+		o.ARR = int(MustParseRepeat(opts.Arr()) / time.Millisecond)
+	}
+	if opts.HasSoftDropFactor() {
+		o.SoftDropFactor = opts.SoftDropFactor()
+	}
+	if opts.HasVolume() {
+		o.Volume = opts.Volume()
+	}
+	if opts.HasMusicVolume() {
+		o.MusicVolume = opts.MusicVolume()
+	}
+	if opts.HasSfxVolume() {
+		o.SFXVolume = opts.SfxVolume()
+	}
+	if opts.HasGhost() {
+		o.Ghost = opts.Ghost()
+	}
+	if opts.HasGrid() {
+		o.Grid = opts.Grid()
+	}
+	if opts.HasSkin() {
+		o.Skin = opts.Skin()
+	}
+	if opts.HasPreviews() {
+		o.Previews = opts.Previews()
+	}
+	if opts.HasRotate180() {
+		o.Rotate180 = opts.Rotate180()
+	}
+	if opts.HasShowFps() {
+		o.ShowFPS = opts.ShowFps()
+	}
+	if opts.HasControls() {
+		o.Controls = opts.Controls()
+	}
+	return o
+}
+
+// apply sets the rules of the game chosen by the options
+func (o Options) apply(cfg sim.Config) sim.Config {
+	cfg.Previews = o.Previews
+	cfg.Allow180 = o.Rotate180
+	cfg.SoftDropFactor = float64(o.SoftDropFactor)
+	return sim.ClampConfig(cfg)
+}
+
+// repeat is how long left and right are held before they repeat, and
+// then how often
+func (o Options) repeat() sim.RepeatRules {
+	return sim.RepeatRules{
+		DAS: time.Duration(o.DAS) * time.Millisecond,
+		ARR: time.Duration(o.ARR) * time.Millisecond,
+	}
+}
+
+// MustParseRepeat reads the milliseconds, or the frames, of a DAS or
+// ARR flag.  Frames round down to whole milliseconds in the settings,
+// which still repeat on the same frame.
+func MustParseRepeat(s string) time.Duration {
+	d, err := sim.ParseRepeat(s, sim.DefaultConfig(0).Tick)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// volumes are the levels of the audio buses from 0 to 1
+func (o Options) volumes() Volumes {
+	return Volumes{
+		Master: float64(o.Volume) / MaxVolume,
+		Music:  float64(o.MusicVolume) / MaxVolume,
+		SFX:    float64(o.SFXVolume) / MaxVolume,
+	}
+}
+
+// SettingsPath is the settings file in the config dir of the user,
+// $XDG_CONFIG_HOME or ~/.config on Linux
+func SettingsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ebiten-01", "settings.yaml"), nil
+}
+
+// LoadSettings reads the options saved in the settings file, where
+// options left out, or a file that does not exist yet, keep their
+// defaults
+func LoadSettings(path string) (Options, error) {
+	o := DefaultOptions()
+	bin, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return o, nil
+	}
+	if err != nil {
+		return o, err
+	}
+	err = yaml.Unmarshal(bin, &o)
+	if err != nil {
+		return DefaultOptions(), fmt.Errorf("reading settings %s: %w", path, err)
+	}
+	return o.clamp(), nil
+}
+
+// SaveSettings writes the options to the settings file, creating its
+// directory when needed
+func SaveSettings(path string, o Options) error {
+	bin, err := yaml.Marshal(o)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, bin, 0o644)
+}
+
+// loadSettings reads the settings of the user, falling back on the
+// defaults when the file can't be read so that a bad file never keeps
+// the game from starting
+func loadSettings() (Options, string) {
+	path, err := SettingsPath()
+	if err != nil {
+		log.Printf("no settings file: %v", err)
+		return DefaultOptions(), ""
+	}
+	o, err := LoadSettings(path)
+	if err != nil {
+		log.Printf("using default settings: %v", err)
+	}
+	return o, path
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lcaballero/ebiten-01/sim"
+	"github.com/stretchr/testify/assert"
+)
+
+// flagVals are the flags given on the command line, by name
+type flagVals map[string]interface{}
+
+func (v flagVals) IsSet(name string) bool {
+	_, ok := v[name]
+	return ok
+}
+func (v flagVals) Bool(name string) bool {
+	b, _ := v[name].(bool)
+	return b
+}
+func (v flagVals) Int(name string) int {
+	n, _ := v[name].(int)
+	return n
+}
+func (v flagVals) Int64(name string) int64 {
+	n, _ := v[name].(int64)
+	return n
+}
+func (v flagVals) String(name string) string {
+	s, _ := v[name].(string)
+	return s
+}
+
+func Test_Settings_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ebiten-01", "settings.yaml")
+	o, err := LoadSettings(path)
+	assert.NoError(t, err, "a missing file keeps the defaults")
+	assert.Equal(t, DefaultOptions(), o)
+
+	o.DAS = 100
+	o.Ghost = true
+	o.Skin = 3
+	o.Controls = WASDPreset
+	assert.NoError(t, SaveSettings(path, o))
+	read, err := LoadSettings(path)
+	assert.NoError(t, err)
+	assert.Equal(t, o, read)
+}
+
+func Test_LoadSettings(t *testing.T) {
+	table := []struct {
+		name     string
+		data     string
+		expected func(o Options) Options
+		fails    bool
+	}{
+		{
+			name:     "left out keeps the defaults",
+			data:     "ghost: true\n",
+			expected: func(o Options) Options { o.Ghost = true; return o },
+		},
+		{
+			name: "out of range",
+			data: "das: -5\narr: 9000\nvolume: 150\nsfx-volume: -10\nskin: 12\npreviews: 0\ncontrols: \"\"\n",
+			expected: func(o Options) Options {
+				o.DAS = 0
+				o.ARR = MaxRepeat
+				o.Volume = MaxVolume
+				o.SFXVolume = 0
+				o.Skin = skins
+				o.Previews = sim.MinPreviews
+				return o
+			},
+		},
+		{name: "not yaml", data: "das: [", fails: true},
+	}
+	for _, row := range table {
+		t.Run(row.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "settings.yaml")
+			assert.NoError(t, os.WriteFile(path, []byte(row.data), 0o644))
+			o, err := LoadSettings(path)
+			if row.fails {
+				assert.Error(t, err)
+				assert.Equal(t, DefaultOptions(), o)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, row.expected(DefaultOptions()), o)
+		})
+	}
+}
+
+func Test_Options_WithFlags(t *testing.T) {
+	settings := DefaultOptions()
+	settings.Ghost = true
+	settings.Volume = 20
+	settings.Controls = WASDPreset
+
+	opts := NewGameOpts{vals: flagVals{"ghost": false, "das": "50", "arr": "10f", "controls": "mine.yaml"}}
+	o := settings.withFlags(opts)
+	assert.False(t, o.Ghost, "flags win over the settings")
+	assert.Equal(t, 50, o.DAS)
+	assert.Equal(t, 166, o.ARR, "frames in whole milliseconds")
+	assert.Equal(t, "mine.yaml", o.Controls)
+	assert.Equal(t, 20, o.Volume, "settings stay without a flag")
+}
+
+func Test_Options_Apply(t *testing.T) {
+	cfg := sim.DefaultConfig(1)
+	cfg = Options{Previews: 9, Rotate180: true, SoftDropFactor: 10}.apply(cfg)
+	assert.Equal(t, sim.MaxPreviews, cfg.Previews)
+	assert.True(t, cfg.Allow180)
+	assert.Equal(t, 10.0, cfg.SoftDropFactor)
+}
+
+func Test_Options_Volumes(t *testing.T) {
+	o := Options{Volume: 50, MusicVolume: 40, SFXVolume: 100}
+	v := o.volumes()
+	assert.Equal(t, Volumes{Master: .5, Music: .4, SFX: 1}, v)
+	assert.InDelta(t, .2, v.music(), 1e-9)
+	assert.InDelta(t, .5, v.sfx(), 1e-9)
+}
+
+func Test_ControlChoices(t *testing.T) {
+	assert.Equal(t, Presets, controlChoices(DefaultPreset))
+	choices := controlChoices("mine.yaml")
+	assert.Equal(t, []string{DefaultPreset, WASDPreset, "mine.yaml"}, choices)
+	assert.Equal(t, WASDPreset, cycle(choices, "mine.yaml", -1))
+	assert.Equal(t, DefaultPreset, cycle(choices, "mine.yaml", 1))
+}
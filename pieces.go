@@ -8,6 +8,13 @@ import (
 	"github.com/lcaballero/ebiten-01/rand"
 )
 
+// blockSize is the width and height in pixels of each block in
+// blocks.png
+const blockSize = 10
+
+// skins is the number of blocks in blocks.png, one for each skin
+const skins = 7
+
 type Cells []*ebiten.Image
 
 func (c Cells) Pick(rnd rand.Rnd) *ebiten.Image {
@@ -36,10 +43,10 @@ func NewPieces() *Pieces {
 		panic(err)
 	}
 	all := ebiten.NewImageFromImage(img)
-	blocks := make([]*ebiten.Image, 7)
-	for i := 0; i < 7; i++ {
-		min := image.Point{X: i * 10}
-		max := image.Point{X: min.X + 10, Y: min.Y + 10}
+	blocks := make([]*ebiten.Image, skins)
+	for i := 0; i < skins; i++ {
+		min := image.Point{X: i * blockSize}
+		max := image.Point{X: min.X + blockSize, Y: min.Y + blockSize}
 		s := image.Rectangle{Min: min, Max: max}
 		sub := all.SubImage(s)
 		block := sub.(*ebiten.Image)
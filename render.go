@@ -0,0 +1,97 @@
+package main
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/lcaballero/ebiten-01/shapes"
+	"github.com/lcaballero/ebiten-01/sim"
+)
+
+// ghostAlpha is how opaque the ghost piece is drawn
+const ghostAlpha = 0.3
+
+func drawScaledBlock(screen, img *ebiten.Image, pos shapes.Vec, scale float64, alpha float32) {
+	m := &ebiten.GeoM{}
+	m.Scale(scale, scale)
+	m.Translate(pos.Components())
+	opts := &ebiten.DrawImageOptions{GeoM: *m}
+	opts.ColorScale.ScaleAlpha(alpha)
+	screen.DrawImage(img, opts)
+}
+
+// skin is the image of a block with the skin, unless the options draw
+// every block with the same skin
+func (b *Game) skin(n int) *ebiten.Image {
+	if b.options.Skin > 0 {
+		n = b.options.Skin - 1
+	}
+	return b.pieces.blocks.At(n)
+}
+
+// toPixels converts the cell on the board to the pixel position of
+// its top left corner
+func (b *Game) toPixels(c sim.Cell) shapes.Vec {
+	size := b.background.size
+	return b.background.board.Pos.Add(shapes.Vec{float64(c.Col) * size, float64(c.Row) * size})
+}
+
+// visibleBoard is the part of the screen showing the visible rows of
+// the board, which clips the blocks in the hidden rows above it so they
+// never cover the HUD or run off the screen
+func (b *Game) visibleBoard(screen *ebiten.Image) *ebiten.Image {
+	x, y, w, h := b.background.board.Components()
+	r := image.Rect(int(x), int(y), int(x+w), int(y+h))
+	return screen.SubImage(r).(*ebiten.Image)
+}
+
+// drawShape draws each block of the shape, at size pixels per cell,
+// offset from the origin
+func (b *Game) drawShape(screen *ebiten.Image, p sim.Piece, origin shapes.Vec, size float64, alpha float32) {
+	img := b.skin(p.Skin)
+	scale := size / blockSize
+	for _, s := range p.Shape {
+		drawScaledBlock(screen, img, origin.Add(s.Scale(size, size)), scale, alpha)
+	}
+}
+
+func (b *Game) drawPiece(screen *ebiten.Image, p sim.Piece) {
+	b.drawShape(screen, p, b.toPixels(p.Cell), b.background.size, 1)
+}
+
+// drawGhost draws the translucent piece showing where the current
+// piece will land
+func (b *Game) drawGhost(screen *ebiten.Image, p sim.Piece) {
+	b.drawShape(screen, p, b.toPixels(p.Cell), b.background.size, ghostAlpha)
+}
+
+func (b *Game) drawMarks(screen *ebiten.Image, marks []sim.Mark) {
+	scale := b.background.size / blockSize
+	for _, m := range marks {
+		drawScaledBlock(screen, b.skin(m.Skin), b.toPixels(m.Cell), scale, 1)
+	}
+}
+
+// drawPreview draws the Piece centered in the given box
+func (b *Game) drawPreview(screen *ebiten.Image, p sim.Piece, box shapes.Rect) {
+	size := b.background.previewSize(box)
+	x, y, w, h := p.Shape.FindHull()
+	min := shapes.Vec{x, y}.Scale(size, size)
+	center := shapes.Vec{w + 1, h + 1}.Scale(size, size).Half()
+	pos := box.Center().Sub(center).Sub(min)
+	b.drawShape(screen, p, pos, size, 1)
+}
+
+// drawQueue draws the next piece large followed by the rest of the
+// upcoming pieces stacked smaller below it
+func (b *Game) drawQueue(screen *ebiten.Image, queue []sim.Piece) {
+	if len(queue) == 0 {
+		return
+	}
+	b.drawPreview(screen, queue[0], b.background.next)
+	for i, box := range b.background.queue {
+		if i+1 < len(queue) {
+			b.drawPreview(screen, queue[i+1], box)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/lcaballero/ebiten-01/sim"
+)
+
+// MustLoadReplay reads the replay file
+func MustLoadReplay(path string) sim.Replay {
+	log.Printf("loading replay: %s", path)
+	f, err := os.Open(path)
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+	replay, err := sim.ReadReplay(f)
+	if err != nil {
+		panic(err)
+	}
+	return replay
+}
+
+// saveRecording writes the inputs recorded so far to the file given
+// by the record flag
+func (b *Game) saveRecording() error {
+	if !b.record || b.recorder == nil {
+		return nil
+	}
+	path := b.opts.Record()
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	err = sim.WriteReplay(f, b.recorder.Replay())
+	if err != nil {
+		return err
+	}
+	log.Printf("recorded replay: %s", path)
+	return nil
+}
@@ -0,0 +1,374 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/lcaballero/ebiten-01/sim"
+)
+
+// Scene is a screen of the game, such as the title or the playfield,
+// that reads its own input and then updates and draws itself.  The
+// Game runs the active scene until it switches to another.
+type Scene interface {
+	Update() error
+	Draw(screen *ebiten.Image)
+}
+
+// menuScene is a scene run entirely by its menu, where back goes to
+// the previous scene
+type menuScene struct {
+	g    *Game
+	menu *Menu
+	back func() // when nil back does nothing
+}
+
+func (s *menuScene) Update() error {
+	for _, k := range s.g.menu.Pressed() {
+		if !s.menu.Handle(k) && k == MenuBack && s.back != nil {
+			s.back()
+		}
+		if s.g.scene != s {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *menuScene) Draw(screen *ebiten.Image) {
+	screen.Fill(color.RGBA{R: 255, A: 255})
+	drawMenu(screen, s.g.background.canvas, s.menu)
+}
+
+// newTitleScene is the first screen, leading to the modes, the options
+// or out of the game
+func newTitleScene(g *Game) Scene {
+	return &menuScene{
+		g: g,
+		menu: NewMenu("Tetris",
+			MenuItem{Label: "Play", Select: func() { g.switchTo(newModesScene(g)) }},
+			MenuItem{Label: "Options", Select: func() { g.switchTo(newOptionsScene(g)) }},
+			MenuItem{Label: "Quit", Select: func() { g.quit = true }},
+		),
+	}
+}
+
+// modeLabel describes the goal of the mode
+func modeLabel(m sim.Mode) string {
+	switch m {
+	case sim.Sprint:
+		return fmt.Sprintf("Sprint %d lines", sim.SprintLines)
+	case sim.Ultra:
+		return fmt.Sprintf("Ultra %d minutes", int(sim.UltraTime.Minutes()))
+	default:
+		return "Marathon"
+	}
+}
+
+// newModesScene picks the mode of the game to start
+func newModesScene(g *Game) Scene {
+	items := []MenuItem{}
+	for _, m := range sim.Modes {
+		mode := m
+		items = append(items, MenuItem{Label: modeLabel(mode), Select: func() { g.start(mode) }})
+	}
+	return &menuScene{
+		g:    g,
+		menu: NewMenu("Mode", items...),
+		back: func() { g.switchTo(newTitleScene(g)) },
+	}
+}
+
+// newOptionsScene changes the settings, putting them into effect and
+// saving them on the way back to the title
+func newOptionsScene(g *Game) Scene {
+	o := &g.settings
+	back := func() {
+		g.applySettings()
+		g.switchTo(newTitleScene(g))
+	}
+	return &menuScene{
+		g: g,
+		menu: NewMenu("Options",
+			number("DAS", "%d ms", &o.DAS, 10, 0, MaxRepeat),
+			number("ARR", "%d ms", &o.ARR, 5, 0, MaxRepeat),
+			number("Soft drop", "%dx", &o.SoftDropFactor, 1, 1, MaxSoftDropFactor),
+			volume(g, "Volume", &o.Volume),
+			volume(g, "Music", &o.MusicVolume),
+			volume(g, "Effects", &o.SFXVolume),
+			toggle("Ghost", &o.Ghost),
+			toggle("Grid", &o.Grid),
+			MenuItem{
+				Label: "Skin",
+				Value: func() string {
+					if o.Skin == 0 {
+						return "each piece"
+					}
+					return fmt.Sprintf("%d", o.Skin)
+				},
+				Change: func(step int) {
+					n := skins + 1
+					o.Skin = (o.Skin + step + n) % n
+				},
+			},
+			number("Previews", "%d", &o.Previews, 1, sim.MinPreviews, sim.MaxPreviews),
+			toggle("Rotate 180", &o.Rotate180),
+			toggle("Show FPS", &o.ShowFPS),
+			MenuItem{
+				Label:  "Controls",
+				Value:  func() string { return o.Controls },
+				Change: func(step int) { o.Controls = cycle(controlChoices(o.Controls), o.Controls, step) },
+			},
+			MenuItem{Label: "Back", Select: back},
+		),
+		back: back,
+	}
+}
+
+// number is a menu item changing the number by the step, between lo
+// and hi
+func number(label, format string, n *int, step, lo, hi int) MenuItem {
+	return MenuItem{
+		Label:  label,
+		Value:  func() string { return fmt.Sprintf(format, *n) },
+		Change: func(dir int) { *n = clamp(*n+dir*step, lo, hi) },
+	}
+}
+
+// volume is a menu item changing the level of an audio bus, trying it
+// out with the lock effect as it changes
+func volume(g *Game, label string, n *int) MenuItem {
+	item := number(label, "%d%%", n, 10, 0, MaxVolume)
+	change := item.Change
+	item.Change = func(dir int) {
+		change(dir)
+		g.audio.SetVolumes(g.settings.volumes())
+		g.audio.Play(EffectLock)
+	}
+	return item
+}
+
+// toggle is a menu item turning the setting on or off
+func toggle(label string, b *bool) MenuItem {
+	return MenuItem{
+		Label:  label,
+		Value:  func() string { return onOff(*b) },
+		Change: func(int) { *b = !*b },
+	}
+}
+
+// controlChoices are the presets, along with the controls file in use
+// when there is one
+func controlChoices(current string) []string {
+	for _, p := range Presets {
+		if p == current {
+			return Presets
+		}
+	}
+	return append(append([]string{}, Presets...), current)
+}
+
+// cycle moves from the current choice by the step, wrapping around
+func cycle(choices []string, current string, step int) string {
+	for i, c := range choices {
+		if c == current {
+			return choices[(i+step+len(choices))%len(choices)]
+		}
+	}
+	return choices[0]
+}
+
+// playScene runs the sim from the controls, or from a replay
+type playScene struct {
+	g *Game
+}
+
+func newPlayScene(g *Game) Scene {
+	return &playScene{g: g}
+}
+
+func (s *playScene) Update() error {
+	g := s.g
+	g.clearTime += g.tick
+	actions := g.actions()
+	if g.quit {
+		return nil
+	}
+	tick := g.sim.Tick()
+	if g.player != nil {
+		if paused(actions) {
+			g.switchTo(newPausedScene(g))
+			return nil
+		}
+		if g.player.Done(tick) {
+			g.switchTo(newResultsScene(g, 0))
+			return nil
+		}
+		actions = g.player.Actions(tick)
+	}
+	g.advance(actions...)
+	switch {
+	case g.sim.IsOver():
+		g.switchTo(newResultsScene(g, g.keepScore()))
+	case g.sim.Paused() && g.player == nil:
+		g.switchTo(newPausedScene(g))
+	}
+	return nil
+}
+
+func (s *playScene) Draw(screen *ebiten.Image) {
+	s.g.drawPlay(screen)
+}
+
+// paused reports if pause was pressed
+func paused(actions []sim.Action) bool {
+	for _, a := range actions {
+		if a == sim.Pause {
+			return true
+		}
+	}
+	return false
+}
+
+// pausedScene is drawn over the playfield, which stands still until
+// play resumes.  The sim is paused and still stepped each tick, so
+// that the pause is recorded along with the rest of the game, while
+// a replay being watched just stops until it resumes.
+type pausedScene struct {
+	g      *Game
+	menu   *Menu
+	resume bool // chosen on this tick
+}
+
+func newPausedScene(g *Game) Scene {
+	s := &pausedScene{g: g}
+	s.menu = NewMenu("Paused",
+		MenuItem{Label: "Resume", Select: func() { s.resume = true }},
+		MenuItem{Label: "Restart", Select: func() { g.start(g.sim.Config().Mode) }},
+		MenuItem{Label: "Quit to title", Select: func() { g.switchTo(newTitleScene(g)) }},
+	)
+	return s
+}
+
+func (s *pausedScene) Update() error {
+	g := s.g
+	s.resume = paused(g.actions())
+	if g.quit {
+		return nil
+	}
+	for _, k := range g.menu.Pressed() {
+		if !s.menu.Handle(k) && k == MenuBack {
+			s.resume = true
+		}
+		if g.scene != s {
+			return nil
+		}
+	}
+	if g.player != nil {
+		if s.resume {
+			g.switchTo(newPlayScene(g))
+		}
+		return nil
+	}
+	if s.resume {
+		g.advance(sim.Pause)
+	} else {
+		g.advance()
+	}
+	if !g.sim.Paused() {
+		g.switchTo(newPlayScene(g))
+	}
+	return nil
+}
+
+func (s *pausedScene) Draw(screen *ebiten.Image) {
+	s.g.drawPlay(screen)
+	dim(screen)
+	drawMenu(screen, s.g.background.canvas, s.menu)
+}
+
+// resultsScene is drawn over the playfield once the game is over,
+// showing how it went and the best scores of the mode
+type resultsScene struct {
+	g     *Game
+	menu  *Menu
+	state sim.State
+	rank  int // of the game in the high scores, or 0
+}
+
+// shownScores is the number of high scores on the results screen
+const shownScores = 5
+
+func newResultsScene(g *Game, rank int) Scene {
+	mode := g.sim.Config().Mode
+	state := g.sim.State()
+	title := "Game Over"
+	if state.TopOut == sim.Finished {
+		title = "Complete"
+	}
+	return &resultsScene{
+		g:     g,
+		state: state,
+		rank:  rank,
+		menu: NewMenu(title,
+			MenuItem{Label: "Play again", Select: func() { g.start(mode) }},
+			MenuItem{Label: "Modes", Select: func() { g.switchTo(newModesScene(g)) }},
+			MenuItem{Label: "Title", Select: func() { g.switchTo(newTitleScene(g)) }},
+		),
+	}
+}
+
+func (s *resultsScene) Update() error {
+	for _, k := range s.g.menu.Pressed() {
+		if !s.menu.Handle(k) && k == MenuBack {
+			s.g.switchTo(newTitleScene(s.g))
+		}
+		if s.g.scene != s {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *resultsScene) Draw(screen *ebiten.Image) {
+	s.g.drawPlay(screen)
+	dim(screen)
+	about := results(s.state)
+	if top := scoreLines(s.g.scores, s.state.Mode, s.rank, shownScores); len(top) > 0 {
+		about = append(append(about, ""), top...)
+	}
+	drawMenu(screen, s.g.background.canvas, s.menu, about...)
+}
+
+// results describes how the game went
+func results(state sim.State) []string {
+	return []string{
+		modeLabel(state.Mode),
+		fmt.Sprintf("Score %-8d Lines %d", state.Score.Score, state.Score.Lines),
+		fmt.Sprintf("Level %-8d Time  %s", state.Score.Level, formatTime(state.Time)),
+	}
+}
+
+// status shows the progress toward the goal of the mode on the HUD
+func status(state sim.State) string {
+	switch state.Mode {
+	case sim.Sprint:
+		left := sim.SprintLines - state.Score.Lines
+		if left < 0 {
+			left = 0
+		}
+		return fmt.Sprintf("%d left", left)
+	case sim.Ultra:
+		return formatTime(sim.UltraTime - state.Time)
+	default:
+		return formatTime(state.Time)
+	}
+}
+
+// formatTime shows the duration in minutes, seconds and hundredths
+func formatTime(d time.Duration) string {
+	d = d.Round(10 * time.Millisecond)
+	return fmt.Sprintf("%d:%05.2f", d/time.Minute, (d % time.Minute).Seconds())
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lcaballero/ebiten-01/sim"
+)
+
+// DataDir is where the game keeps the files it writes for the user,
+// $XDG_DATA_HOME or ~/.local/share on Linux, and the config dir on
+// Windows and macOS which have no separate place for data
+func DataDir() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "ebiten-01"), nil
+	}
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, "ebiten-01"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "ebiten-01"), nil
+}
+
+// ScoresPath is the high scores file in the data dir of the user
+func ScoresPath() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "scores.json"), nil
+}
+
+// LoadHighScores reads the high scores file, where a file that does
+// not exist yet holds no scores
+func LoadHighScores(path string) (*sim.HighScores, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return sim.NewHighScores(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h, err := sim.ReadHighScores(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return h, nil
+}
+
+// SaveHighScores writes the high scores file, creating its directory
+// when needed
+func SaveHighScores(path string, h *sim.HighScores) error {
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return sim.WriteHighScores(f, h)
+}
+
+// loadHighScores reads the scores of the user, or nil when they can't
+// be read so that a bad file is left alone rather than overwritten
+func loadHighScores() (*sim.HighScores, string) {
+	path, err := ScoresPath()
+	if err != nil {
+		log.Printf("no high scores file: %v", err)
+		return nil, ""
+	}
+	h, err := LoadHighScores(path)
+	if err != nil {
+		log.Printf("not keeping high scores: %v", err)
+		return nil, ""
+	}
+	return h, path
+}
+
+// replayPath names the replay kept with a high score in the replays
+// dir next to the scores file
+func replayPath(scoresPath string, mode sim.Mode, e sim.Entry) string {
+	name := fmt.Sprintf("%s-%s-%d.json", mode, e.Date.Format("20060102-150405"), e.Seed)
+	return filepath.Join(filepath.Dir(scoresPath), "replays", name)
+}
+
+// writeReplay writes the replay to the file, creating its directory
+// when needed
+func writeReplay(path string, r sim.Replay) error {
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return sim.WriteReplay(f, r)
+}
+
+// removeReplays deletes the replays of the entries dropped from the
+// high scores
+func removeReplays(entries []sim.Entry) {
+	for _, e := range entries {
+		if e.Replay == "" {
+			continue
+		}
+		err := os.Remove(e.Replay)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("removing replay: %v", err)
+		}
+	}
+}
+
+// keepScore adds the game that just ended to the high scores, saving
+// its replay along with it, and reports its rank from 1, or 0 when it
+// did not make the table
+func (b *Game) keepScore() int {
+	if b.scores == nil || b.recorder == nil || b.replay != nil {
+		return 0
+	}
+	cfg := b.sim.Config()
+	e := sim.NewEntry(b.sim.State(), cfg, time.Now())
+	e.Replay = replayPath(b.scoresPath, cfg.Mode, e)
+	top := b.scores.Top(cfg.Mode)
+	dropped := []sim.Entry{}
+	if len(top) == sim.MaxScores {
+		dropped = append(dropped, top[len(top)-1])
+	}
+	rank := b.scores.Add(cfg.Mode, e)
+	if rank == 0 {
+		return 0
+	}
+	removeReplays(dropped)
+	err := writeReplay(e.Replay, b.recorder.Replay())
+	if err != nil {
+		log.Printf("saving replay: %v", err)
+		b.scores.Top(cfg.Mode)[rank-1].Replay = ""
+	}
+	err = SaveHighScores(b.scoresPath, b.scores)
+	if err != nil {
+		log.Printf("saving high scores: %v", err)
+		return rank
+	}
+	log.Printf("high score #%d in %s: %s", rank, cfg.Mode, b.scoresPath)
+	return rank
+}
+
+// scoreLines shows the best entries of the mode on the results screen,
+// marking the entry at the rank
+func scoreLines(h *sim.HighScores, mode sim.Mode, rank, n int) []string {
+	if h == nil {
+		return nil
+	}
+	lines := []string{}
+	for i, e := range h.Top(mode) {
+		if i == n {
+			break
+		}
+		mark := " "
+		if i+1 == rank {
+			mark = "*"
+		}
+		lines = append(lines, fmt.Sprintf("%s%2d %7d %3d %2d %s",
+			mark, i+1, e.Score, e.Lines, e.Level, formatTime(e.Duration)))
+	}
+	return lines
+}
+
+// WriteScores lists the high scores of each mode, or of just the mode
+// when one is given
+func WriteScores(w io.Writer, h *sim.HighScores, mode sim.Mode) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, m := range sim.Modes {
+		if mode != 0 && m != mode {
+			continue
+		}
+		fmt.Fprintf(tw, "%s\n", modeLabel(m))
+		top := h.Top(m)
+		if len(top) == 0 {
+			fmt.Fprintf(tw, "  no scores yet\n\n")
+			continue
+		}
+		fmt.Fprintf(tw, "  #\tscore\tlines\tlevel\ttime\tdate\tseed\treplay\n")
+		for i, e := range top {
+			fmt.Fprintf(tw, "  %d\t%d\t%d\t%d\t%s\t%s\t%d\t%s\n",
+				i+1, e.Score, e.Lines, e.Level, formatTime(e.Duration),
+				e.Date.Local().Format("2006-01-02 15:04"), e.Seed, e.Replay)
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lcaballero/ebiten-01/sim"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_HighScores_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ebiten-01", "scores.json")
+	h, err := LoadHighScores(path)
+	assert.NoError(t, err, "a missing file has no scores")
+	assert.Empty(t, h.Top(sim.Marathon))
+
+	h.Add(sim.Marathon, sim.Entry{Score: 100, Seed: 3})
+	assert.NoError(t, SaveHighScores(path, h))
+	read, err := LoadHighScores(path)
+	assert.NoError(t, err)
+	assert.Equal(t, h, read)
+
+	assert.NoError(t, os.WriteFile(path, []byte("{"), 0o644))
+	_, err = LoadHighScores(path)
+	assert.Error(t, err)
+}
+
+func Test_ScoreLines(t *testing.T) {
+	h := sim.NewHighScores()
+	for i := 1; i <= 7; i++ {
+		h.Add(sim.Marathon, sim.Entry{Score: i * 100, Lines: i, Level: 1, Duration: time.Duration(i) * time.Minute})
+	}
+	lines := scoreLines(h, sim.Marathon, 2, 5)
+	assert.Len(t, lines, 5)
+	assert.Equal(t, "  1     700   7  1 7:00.00", lines[0])
+	assert.Equal(t, "* 2     600   6  1 6:00.00", lines[1])
+	assert.Empty(t, scoreLines(h, sim.Sprint, 0, 5))
+	assert.Nil(t, scoreLines(nil, sim.Marathon, 1, 5))
+}
+
+func Test_WriteScores(t *testing.T) {
+	h := sim.NewHighScores()
+	date := time.Date(2024, 3, 1, 12, 30, 0, 0, time.Local)
+	h.Add(sim.Sprint, sim.Entry{Score: 900, Lines: 40, Level: 5, Duration: time.Minute, Finished: true, Date: date, Seed: 7, Replay: "r.json"})
+	buf := &bytes.Buffer{}
+	assert.NoError(t, WriteScores(buf, h, sim.Sprint))
+	assert.Equal(t, "Sprint 40 lines\n"+
+		"  #  score  lines  level  time     date              seed  replay\n"+
+		"  1  900    40     5      1:00.00  2024-03-01 12:30  7     r.json\n"+
+		"\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, WriteScores(buf, h, 0))
+	assert.Contains(t, buf.String(), "Marathon\n  no scores yet\n")
+	assert.Contains(t, buf.String(), "Ultra 2 minutes\n  no scores yet\n")
+}
+
+func Test_ReplayPath(t *testing.T) {
+	e := sim.Entry{Date: time.Date(2024, 3, 1, 12, 30, 5, 0, time.UTC), Seed: 7}
+	path := replayPath(filepath.Join("data", "scores.json"), sim.Ultra, e)
+	assert.Equal(t, filepath.Join("data", "replays", "ultra-20240301-123005-7.json"), path)
+}
@@ -57,6 +57,9 @@ func Cap(s string) string {
 }
 
 func DefaultValue(ty string, t interface{}) string {
+	if t == nil {
+		return ""
+	}
 	s, isString := t.(string)
 	if isString && strings.TrimSpace(s) == "" {
 		return ""
@@ -193,9 +196,13 @@ func NewApp(procs Procs) *cli.App {
 }
 
 func (w *Writer) subs(subs []SubCommand) string {
-	buf := `cli.Command{
+	buf := ""
+	for i, cmd := range subs {
+		if i > 0 {
+			buf += "\n\t\t\t"
+		}
+		buf += `cli.Command{
 `
-	for _, cmd := range subs {
 		s := TrimLeft(`
 				Name: "%s",
 				Usage: "%s",
@@ -223,12 +230,8 @@ func (w *Writer) subs(subs []SubCommand) string {
 			)
 		}
 		buf += `				},
-`
-	}
-	s := `
 			},`
-	s = strings.TrimLeft(s, "\n")
-	buf += s
+	}
 	return buf
 }
 
@@ -0,0 +1,84 @@
+package sim
+
+import "fmt"
+
+// Action is an abstract input, decoupled from any keyboard or
+// controller, that the simulation applies at the start of a tick
+type Action int
+
+const (
+	MoveLeft   Action = 1
+	MoveRight  Action = 2
+	RotateCW   Action = 3
+	SoftDrop   Action = 4
+	ResetPiece Action = 5
+	Pause      Action = 6
+	Restart    Action = 7
+	RotateCCW  Action = 8
+	Rotate180  Action = 9
+	HardDrop   Action = 10
+	Hold       Action = 11
+	MoveDown   Action = 12
+)
+
+func (a Action) String() string {
+	switch a {
+	case MoveLeft:
+		return "move-left"
+	case MoveRight:
+		return "move-right"
+	case RotateCW:
+		return "rotate-cw"
+	case SoftDrop:
+		return "soft-drop"
+	case ResetPiece:
+		return "reset-piece"
+	case Pause:
+		return "pause"
+	case Restart:
+		return "restart"
+	case RotateCCW:
+		return "rotate-ccw"
+	case Rotate180:
+		return "rotate-180"
+	case HardDrop:
+		return "hard-drop"
+	case Hold:
+		return "hold"
+	case MoveDown:
+		return "move-down"
+	default:
+		return "unknown"
+	}
+}
+
+// actions lists every Action for parsing names
+var actions = []Action{
+	MoveLeft, MoveRight, RotateCW, SoftDrop, ResetPiece, Pause,
+	Restart, RotateCCW, Rotate180, HardDrop, Hold, MoveDown,
+}
+
+// ToAction parses the name of the Action, reporting 0 when the name
+// is unknown
+func ToAction(s string) Action {
+	for _, a := range actions {
+		if a.String() == s {
+			return a
+		}
+	}
+	return 0
+}
+
+// MarshalText writes the Action by name
+func (a Action) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText reads the Action from its name
+func (a *Action) UnmarshalText(text []byte) error {
+	*a = ToAction(string(text))
+	if *a == 0 {
+		return fmt.Errorf("unknown action %q", text)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package sim
+
+import (
+	"math"
+	"time"
+)
+
+// Board answers the collision questions for a Tetromino against the
+// Grid of locked cells
+type Board struct {
+	grid *Grid
+}
+
+func NewBoard(cols, rows, hidden int) *Board {
+	return &Board{
+		grid: NewGrid(cols, rows, hidden),
+	}
+}
+
+func (b *Board) reset() {
+	b.grid.reset()
+}
+
+// fits reports if the Tetromino in the given rotation and offset by
+// the columns and rows overlaps neither the walls, floor nor stack
+func (b *Board) fits(t *Tetromino, rot Rotation, dc, dr int) bool {
+	for _, c := range t.cellsAt(rot) {
+		if b.grid.Occupied(c.Col+dc, c.Row+dr) {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *Board) CanGoRight(t *Tetromino) bool {
+	return b.fits(t, t.rot, 1, 0)
+}
+
+func (b *Board) CanGoLeft(t *Tetromino) bool {
+	return b.fits(t, t.rot, -1, 0)
+}
+
+// Rotate turns the Tetromino into the given rotation using the first
+// SRS kick that fits and reports the index of that kick in the table
+func (b *Board) Rotate(t *Tetromino, to Rotation) (int, bool) {
+	if t.lock == Locked {
+		return 0, false
+	}
+	for i, k := range kicksFor(t.tetro, t.rot, to) {
+		dc, up := k.IntComponents()
+		if b.fits(t, to, dc, -up) {
+			t.rotateTo(to, i, dc, -up)
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// RotateRight turns the Tetromino clockwise
+func (b *Board) RotateRight(t *Tetromino) (int, bool) {
+	return b.Rotate(t, t.rot.Inc(t.tetro))
+}
+
+// RotateLeft turns the Tetromino counter-clockwise
+func (b *Board) RotateLeft(t *Tetromino) (int, bool) {
+	return b.Rotate(t, t.rot.Dec(t.tetro))
+}
+
+// Rotate180 turns the Tetromino half a turn
+func (b *Board) Rotate180(t *Tetromino) (int, bool) {
+	return b.Rotate(t, t.rot.Flip(t.tetro))
+}
+
+// Drop moves the Tetromino down by the whole cells it has fallen,
+// stopping on top of the stack so that it can never tunnel through,
+// and reports the rows it moved.  The fall left over while resting on
+// the stack is let go of, keeping only the part of a cell, as at high
+// gravity a piece gathers many cells each tick that would otherwise
+// all drop it at once as soon as it slides off a ledge.
+func (b *Board) Drop(t *Tetromino) int {
+	rows := 0
+	for t.fall >= 1 && b.fits(t, t.rot, 0, 1) {
+		t.fall--
+		t.row++
+		rows++
+	}
+	if rows > 0 {
+		t.spun = false
+	}
+	if t.fall >= 1 {
+		t.fall -= math.Floor(t.fall)
+	}
+	t.fell()
+	return rows
+}
+
+// StepDown moves the Tetromino down a row, reporting if it fit
+func (b *Board) StepDown(t *Tetromino) bool {
+	if t.lock == Locked || !b.fits(t, t.rot, 0, 1) {
+		return false
+	}
+	t.row++
+	t.spun = false
+	t.fell()
+	return true
+}
+
+// HardDrop moves the Tetromino to the lowest position it fits and
+// reports the rows it moved
+func (b *Board) HardDrop(t *Tetromino) int {
+	if t.lock == Locked {
+		return 0
+	}
+	land := b.Landing(t)
+	rows := land.Row - t.row
+	t.row = land.Row
+	t.fall = 0
+	if rows > 0 {
+		t.spun = false
+	}
+	return rows
+}
+
+// Landing reports the cell the Tetromino would come to rest on if it
+// dropped straight down from where it is
+func (b *Board) Landing(t *Tetromino) Cell {
+	rows := 0
+	for b.fits(t, t.rot, 0, rows+1) {
+		rows++
+	}
+	return Cell{Col: t.col, Row: t.row + rows}
+}
+
+// CheckBounds advances the lock state of the Tetromino for the tick
+// and locks it into the grid once its lock delay runs out
+func (b *Board) CheckBounds(t *Tetromino, elapsed time.Duration, rules LockRules) {
+	grounded := !b.fits(t, t.rot, 0, 1)
+	if t.touch(grounded, elapsed, rules) {
+		b.Lock(t)
+	}
+}
+
+// Lock freezes the Tetromino into the grid where it is
+func (b *Board) Lock(t *Tetromino) {
+	if t.lock == Locked {
+		return
+	}
+	t.lock = Locked
+	t.fall = 0
+	for _, c := range t.cells() {
+		b.grid.Set(c.Col, c.Row, t.skin)
+	}
+}
+
+// Cleared is what locking a piece did to the board
+type Cleared struct {
+	Rows    []int // the rows removed, top to bottom
+	Spin    Spin
+	Perfect bool // nothing is left on the board
+}
+
+// ClearLines removes the rows completed by the locked Tetromino and
+// reports them along with the spin that put the piece in place
+func (b *Board) ClearLines(t *Tetromino) Cleared {
+	spin := b.Spin(t)
+	rows := b.grid.ClearFullRows()
+	return Cleared{
+		Rows:    rows,
+		Spin:    spin,
+		Perfect: len(rows) > 0 && b.grid.Empty(),
+	}
+}
+
+func (b *Board) Marks() []Mark {
+	return b.grid.Marks()
+}
+
+// BlockedOut reports if the Tetromino, having just entered the board,
+// overlaps the stack
+func (b *Board) BlockedOut(t *Tetromino) bool {
+	return !b.fits(t, t.rot, 0, 0)
+}
+
+// LockedOut reports if every block of the Tetromino is above the top
+// of the board
+func (b *Board) LockedOut(t *Tetromino) bool {
+	for _, c := range t.cells() {
+		if c.Row >= 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,246 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewBoard(t *testing.T) {
+	b := NewBoard(10, 20, 0)
+
+	assert.NotNil(t, b.grid)
+	assert.Equal(t, 10, b.grid.Cols())
+	assert.Equal(t, 20, b.grid.Rows())
+}
+
+func Test_Board_Moves(t *testing.T) {
+	cases := []struct {
+		name     string
+		expected bool
+		piece    *Tetromino
+		setup    func(*Grid)
+		call     func(*Board, *Tetromino) bool
+	}{
+		{
+			name:     "right wall blocks moving right",
+			expected: false,
+			piece:    &Tetromino{tetro: O, rot: R1, col: 7, row: 5},
+			call:     (*Board).CanGoRight,
+		},
+		{
+			name:     "left wall blocks moving left",
+			expected: false,
+			piece:    &Tetromino{tetro: O, rot: R1, col: -1, row: 5},
+			call:     (*Board).CanGoLeft,
+		},
+		{
+			name:     "stack blocks moving left",
+			expected: false,
+			piece:    &Tetromino{tetro: O, rot: R1, col: 4, row: 5},
+			setup: func(g *Grid) {
+				g.Set(4, 5, 0)
+			},
+			call: (*Board).CanGoLeft,
+		},
+		{
+			name:     "open space allows moving right",
+			expected: true,
+			piece:    &Tetromino{tetro: O, rot: R1, col: 4, row: 5},
+			call:     (*Board).CanGoRight,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			b := NewBoard(10, 20, 0)
+			if c.setup != nil {
+				c.setup(b.grid)
+			}
+			assert.Equal(t, c.expected, c.call(b, c.piece))
+		})
+	}
+}
+
+func Test_Board_Rotate(t *testing.T) {
+	cases := []struct {
+		name  string
+		cols  int
+		rows  int
+		piece *Tetromino
+		setup func(*Grid)
+		to    Rotation
+		ok    bool
+		kick  int
+		cell  Cell
+	}{
+		{
+			name:  "open space rotates in place",
+			piece: &Tetromino{tetro: T, rot: R1, col: 4, row: 5},
+			to:    R2,
+			ok:    true,
+			kick:  0,
+			cell:  Cell{4, 5},
+		},
+		{
+			name:  "T against the left wall kicks right",
+			piece: &Tetromino{tetro: T, rot: R2, col: -1, row: 5},
+			to:    R3,
+			ok:    true,
+			kick:  1,
+			cell:  Cell{0, 5},
+		},
+		{
+			name:  "I against the right wall kicks left",
+			piece: &Tetromino{tetro: I, rot: R2, col: 7, row: 5},
+			to:    R3,
+			ok:    true,
+			kick:  1,
+			cell:  Cell{6, 5},
+		},
+		{
+			name:  "I against the left wall kicks right by two",
+			piece: &Tetromino{tetro: I, rot: R2, col: -2, row: 5},
+			to:    R3,
+			ok:    true,
+			kick:  2,
+			cell:  Cell{0, 5},
+		},
+		{
+			name:  "T on the floor kicks up",
+			piece: &Tetromino{tetro: T, rot: R1, col: 4, row: 18},
+			to:    R2,
+			ok:    true,
+			kick:  2,
+			cell:  Cell{3, 17},
+		},
+		{
+			name:  "J kicks down into a slot in the stack",
+			piece: &Tetromino{tetro: J, rot: R1, col: 0, row: 15},
+			setup: func(g *Grid) {
+				for col := 0; col < 10; col++ {
+					for row := 17; row < 20; row++ {
+						if col != 1 && !(col == 2 && row == 17) {
+							g.Set(col, row, 0)
+						}
+					}
+				}
+				g.Set(2, 15, 0)
+				g.Set(1, 14, 0)
+			},
+			to:   R2,
+			ok:   true,
+			kick: 3,
+			cell: Cell{0, 17},
+		},
+		{
+			name:  "T turning left against the right wall kicks left",
+			piece: &Tetromino{tetro: T, rot: R4, col: 8, row: 5},
+			to:    R4.Dec(T),
+			ok:    true,
+			kick:  1,
+			cell:  Cell{7, 5},
+		},
+		{
+			name:  "T half turn on the floor kicks up",
+			piece: &Tetromino{tetro: T, rot: R1, col: 4, row: 18},
+			to:    R1.Flip(T),
+			ok:    true,
+			kick:  1,
+			cell:  Cell{4, 17},
+		},
+		{
+			name:  "surrounded piece does not rotate",
+			cols:  3,
+			rows:  2,
+			piece: &Tetromino{tetro: T, rot: R1, col: 0, row: 0},
+			setup: func(g *Grid) {
+				g.Set(0, 0, 0)
+				g.Set(2, 0, 0)
+			},
+			to:   R2,
+			ok:   false,
+			cell: Cell{0, 0},
+		},
+		{
+			name:  "O never moves when rotating",
+			piece: &Tetromino{tetro: O, rot: R1, col: 7, row: 18},
+			to:    R2,
+			ok:    true,
+			cell:  Cell{7, 18},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if c.cols == 0 {
+				c.cols, c.rows = 10, 20
+			}
+			b := NewBoard(c.cols, c.rows, 0)
+			if c.setup != nil {
+				c.setup(b.grid)
+			}
+			from := c.piece.rot
+			kick, ok := b.Rotate(c.piece, c.to)
+			assert.Equal(t, c.ok, ok)
+			assert.Equal(t, c.kick, kick)
+			assert.Equal(t, c.cell, Cell{c.piece.col, c.piece.row})
+			if ok {
+				assert.Equal(t, c.to, c.piece.rot)
+			} else {
+				assert.Equal(t, from, c.piece.rot)
+			}
+		})
+	}
+}
+
+func Test_Board_Drop(t *testing.T) {
+	b := NewBoard(10, 20, 0)
+	b.grid.Set(5, 10, 0)
+	p := &Tetromino{tetro: O, rot: R1, col: 4, row: 1, fall: 100}
+	b.Drop(p)
+	assert.Equal(t, 8, p.row)
+
+	b.CheckBounds(p, 0, LockRules{})
+	assert.Equal(t, Locked, p.lock)
+	assert.True(t, b.grid.Occupied(5, 8))
+	assert.True(t, b.grid.Occupied(6, 9))
+}
+
+func Test_Board_Drop_Resting(t *testing.T) {
+	b := NewBoard(10, 20, 0)
+	b.grid.Set(5, 10, 0)
+	p := &Tetromino{tetro: O, rot: R1, col: 4, row: 8}
+	for i := 0; i < 10; i++ {
+		p.fall += 20.25 // 20G for a tick
+		assert.Equal(t, 0, b.Drop(p), "resting on the stack")
+	}
+	assert.InDelta(t, 0.5, p.fall, 1e-9, "only the part of a cell is kept")
+
+	// sliding off the ledge falls at the gravity, not all of the fall
+	// gathered while resting
+	p.col = 6
+	assert.Equal(t, 0, b.Drop(p))
+	p.fall += 2
+	assert.Equal(t, 2, b.Drop(p))
+	assert.Equal(t, 10, p.row)
+}
+
+func Test_Board_Landing(t *testing.T) {
+	b := NewBoard(10, 20, 0)
+	b.grid.Set(5, 12, 0)
+	p := &Tetromino{tetro: O, rot: R1, col: 4, row: -1}
+	assert.Equal(t, Cell{4, 10}, b.Landing(p))
+	assert.Equal(t, -1, p.row)
+
+	p.col = 6
+	assert.Equal(t, Cell{6, 18}, b.Landing(p))
+}
+
+func Test_Board_HardDrop(t *testing.T) {
+	b := NewBoard(10, 20, 0)
+	b.grid.Set(6, 12, 0)
+	p := &Tetromino{tetro: O, rot: R1, col: 4, row: -1, fall: 0.5}
+	assert.Equal(t, 11, b.HardDrop(p))
+	assert.Equal(t, 10, p.row)
+	assert.Equal(t, 0.0, p.fall)
+	assert.Equal(t, 0, b.HardDrop(p))
+}
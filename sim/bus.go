@@ -0,0 +1,42 @@
+package sim
+
+// Handler reacts to an Event published on a Bus
+type Handler func(Event)
+
+// Bus delivers each published Event to the handlers subscribed to its
+// kind, and to those subscribed to every kind, in the order they were
+// subscribed
+type Bus struct {
+	handlers map[EventKind][]Handler
+	all      []Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{
+		handlers: map[EventKind][]Handler{},
+	}
+}
+
+// Subscribe calls the handler for each Event of the given kinds, or
+// for every Event when no kinds are given
+func (b *Bus) Subscribe(h Handler, kinds ...EventKind) {
+	if len(kinds) == 0 {
+		b.all = append(b.all, h)
+		return
+	}
+	for _, k := range kinds {
+		b.handlers[k] = append(b.handlers[k], h)
+	}
+}
+
+// Publish delivers the events in order
+func (b *Bus) Publish(events ...Event) {
+	for _, ev := range events {
+		for _, h := range b.handlers[ev.Kind] {
+			h(ev)
+		}
+		for _, h := range b.all {
+			h(ev)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Bus(t *testing.T) {
+	b := NewBus()
+	locked, all := []Event{}, []EventKind{}
+	b.Subscribe(func(ev Event) { locked = append(locked, ev) }, PieceLocked, GameOver)
+	b.Subscribe(func(ev Event) { all = append(all, ev.Kind) })
+
+	b.Publish(Event{Kind: PieceSpawned}, Event{Kind: PieceLocked, Tick: 3}, Event{Kind: GameOver})
+	assert.Equal(t, []Event{{Kind: PieceLocked, Tick: 3}, {Kind: GameOver}}, locked)
+	assert.Equal(t, []EventKind{PieceSpawned, PieceLocked, GameOver}, all)
+}
@@ -1,4 +1,4 @@
-package main
+package sim
 
 import (
 	"testing"
@@ -31,3 +31,17 @@ func Test_Rotation(t *testing.T) {
 	assert.Equal(t, R3.Inc(J), R4)
 	assert.Equal(t, R4.Inc(J), R1)
 }
+
+func Test_Rotation_Dec(t *testing.T) {
+	assert.Equal(t, R1.Dec(J), R4)
+	assert.Equal(t, R4.Dec(J), R3)
+	assert.Equal(t, R3.Dec(J), R2)
+	assert.Equal(t, R2.Dec(J), R1)
+}
+
+func Test_Rotation_Flip(t *testing.T) {
+	assert.Equal(t, R1.Flip(T), R3)
+	assert.Equal(t, R2.Flip(T), R4)
+	assert.Equal(t, R3.Flip(T), R1)
+	assert.Equal(t, R4.Flip(T), R2)
+}
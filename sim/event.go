@@ -0,0 +1,83 @@
+package sim
+
+// EventKind names the things that happen during a tick that code
+// outside of the simulation may want to react to
+type EventKind int
+
+const (
+	PieceLocked  EventKind = 1
+	LinesCleared EventKind = 2
+	GameOver     EventKind = 3
+	PieceDropped EventKind = 4
+	PieceSpawned EventKind = 5
+	PieceMoved   EventKind = 6
+	PieceRotated EventKind = 7
+	LevelUp      EventKind = 8
+	HoldUsed     EventKind = 9
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case PieceLocked:
+		return "piece-locked"
+	case LinesCleared:
+		return "lines-cleared"
+	case GameOver:
+		return "game-over"
+	case PieceDropped:
+		return "piece-dropped"
+	case PieceSpawned:
+		return "piece-spawned"
+	case PieceMoved:
+		return "piece-moved"
+	case PieceRotated:
+		return "piece-rotated"
+	case LevelUp:
+		return "level-up"
+	case HoldUsed:
+		return "hold-used"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is reported from Step for each thing that happened in the
+// tick
+type Event struct {
+	Kind   EventKind
+	Tick   int
+	Tetro  Tetro
+	Rows   []int
+	Score  ScoreBoard
+	TopOut TopOut
+	Cells  int  // cells a piece was dropped by
+	Hard   bool // the drop was a hard drop
+	Clear  Clear
+	Piece  Piece // where the piece is after it spawned, moved or rotated
+}
+
+// TopOut is the way the game ended, most often by the stack reaching
+// the top of the board
+type TopOut int
+
+const (
+	// BlockOut is a new piece entering the board on top of the stack
+	BlockOut TopOut = 1
+	// LockOut is a piece locking entirely above the board
+	LockOut TopOut = 2
+	// Finished is reaching the goal of the Mode
+	Finished TopOut = 3
+)
+
+func (t TopOut) String() string {
+	switch t {
+	case BlockOut:
+		return "block-out"
+	case LockOut:
+		return "lock-out"
+	case Finished:
+		return "finished"
+	default:
+		return "none"
+	}
+}
@@ -0,0 +1,188 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// G is gravity in cells per frame at FramesPerSecond, so that 1G drops
+// a piece a row every frame and 20G drops it to the floor at once
+type G float64
+
+const (
+	FramesPerSecond   = 60
+	MaxGravity      G = 20
+)
+
+// CellsPerSecond converts the gravity to the speed of a falling piece
+func (g G) CellsPerSecond() float64 {
+	return float64(g) * FramesPerSecond
+}
+
+// GravityKind names one of the built in gravity curves
+type GravityKind int
+
+const (
+	// GuidelineGravity speeds up with each level reaching 20G at level 19
+	GuidelineGravity GravityKind = 1
+	// NESGravity counts frames per row as the NES did, starting at its
+	// level 0
+	NESGravity GravityKind = 2
+	// TwentyG drops every piece to the floor at once from the start
+	TwentyG GravityKind = 3
+)
+
+func (k GravityKind) String() string {
+	switch k {
+	case GuidelineGravity:
+		return "guideline"
+	case NESGravity:
+		return "nes"
+	case TwentyG:
+		return "20g"
+	default:
+		return "unknown"
+	}
+}
+
+// ToGravityKind parses the name of the gravity curve defaulting to
+// GuidelineGravity
+func ToGravityKind(s string) GravityKind {
+	switch s {
+	case "nes":
+		return NESGravity
+	case "20g":
+		return TwentyG
+	default:
+		return GuidelineGravity
+	}
+}
+
+// GravityCurve holds the gravity of each level, starting at level 1,
+// where levels past the end of the table use the last entry
+type GravityCurve struct {
+	Name   string
+	Levels []G
+}
+
+func NewGravityCurve(kind GravityKind) GravityCurve {
+	switch kind {
+	case NESGravity:
+		return nesCurve()
+	case TwentyG:
+		return GravityCurve{Name: kind.String(), Levels: []G{MaxGravity}}
+	default:
+		return guidelineCurve()
+	}
+}
+
+// At reports the gravity for the level
+func (c GravityCurve) At(level int) G {
+	if len(c.Levels) == 0 {
+		return 0
+	}
+	i := level - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(c.Levels) {
+		i = len(c.Levels) - 1
+	}
+	return c.Levels[i]
+}
+
+// guidelineCurve uses the seconds per row of (0.8-(level-1)*0.007)^(level-1)
+// up to level 20, capped at 20G
+func guidelineCurve() GravityCurve {
+	levels := make([]G, 20)
+	for i := range levels {
+		n := float64(i)
+		secs := math.Pow(0.8-n*0.007, n)
+		levels[i] = G(math.Min(1/(secs*FramesPerSecond), float64(MaxGravity)))
+	}
+	return GravityCurve{Name: GuidelineGravity.String(), Levels: levels}
+}
+
+// nesFrames is the frames per row for NES levels 0 to 29
+var nesFrames = []int{
+	48, 43, 38, 33, 28, 23, 18, 13, 8, 6,
+	5, 5, 5, 4, 4, 4, 3, 3, 3, 2,
+	2, 2, 2, 2, 2, 2, 2, 2, 2, 1,
+}
+
+func nesCurve() GravityCurve {
+	levels := make([]G, len(nesFrames))
+	for i, f := range nesFrames {
+		levels[i] = G(1 / float64(f))
+	}
+	return GravityCurve{Name: NESGravity.String(), Levels: levels}
+}
+
+// gravityRow is an entry in a YAML gravity table giving the gravity
+// from a level onward, either in G or in frames per row
+type gravityRow struct {
+	Level  int     `yaml:"level"`
+	G      float64 `yaml:"g"`
+	Frames float64 `yaml:"frames"`
+}
+
+type gravityTable struct {
+	Name   string       `yaml:"name"`
+	Levels []gravityRow `yaml:"levels"`
+}
+
+// ParseGravityCurve reads a custom curve from a YAML table such as:
+//
+//	name: slow
+//	levels:
+//	  - level: 1
+//	    frames: 60
+//	  - level: 10
+//	    g: 0.5
+//
+// Each entry holds from its level until the next, and the gravity is
+// capped at MaxGravity.
+func ParseGravityCurve(data []byte) (GravityCurve, error) {
+	table := gravityTable{}
+	err := yaml.Unmarshal(data, &table)
+	if err != nil {
+		return GravityCurve{}, fmt.Errorf("reading gravity table: %w", err)
+	}
+	if len(table.Levels) == 0 {
+		return GravityCurve{}, fmt.Errorf("gravity table %q has no levels", table.Name)
+	}
+	rows := table.Levels
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].Level < rows[j].Level })
+
+	levels := []G{}
+	for i, row := range rows {
+		if row.Level < 1 {
+			return GravityCurve{}, fmt.Errorf("gravity table %q has level %d, levels start at 1", table.Name, row.Level)
+		}
+		if i > 0 && row.Level == rows[i-1].Level {
+			return GravityCurve{}, fmt.Errorf("gravity table %q repeats level %d", table.Name, row.Level)
+		}
+		g := G(row.G)
+		if row.Frames > 0 {
+			g = G(1 / row.Frames)
+		}
+		if g <= 0 {
+			return GravityCurve{}, fmt.Errorf("gravity table %q needs g or frames at level %d", table.Name, row.Level)
+		}
+		if g > MaxGravity {
+			g = MaxGravity
+		}
+		fill := g
+		if len(levels) > 0 {
+			fill = levels[len(levels)-1]
+		}
+		for len(levels) < row.Level-1 {
+			levels = append(levels, fill)
+		}
+		levels = append(levels, g)
+	}
+	return GravityCurve{Name: table.Name, Levels: levels}, nil
+}
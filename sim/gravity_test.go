@@ -0,0 +1,107 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GravityCurve_At(t *testing.T) {
+	cases := []struct {
+		name     string
+		kind     GravityKind
+		level    int
+		expected G
+	}{
+		{name: "guideline starts at a row a second", kind: GuidelineGravity, level: 1, expected: 1.0 / 60},
+		{name: "guideline level 10", kind: GuidelineGravity, level: 10, expected: 0.2598},
+		{name: "guideline reaches 20G", kind: GuidelineGravity, level: 19, expected: MaxGravity},
+		{name: "guideline stays at 20G", kind: GuidelineGravity, level: 40, expected: MaxGravity},
+		{name: "nes level 0", kind: NESGravity, level: 1, expected: 1.0 / 48},
+		{name: "nes level 19", kind: NESGravity, level: 20, expected: 1.0 / 2},
+		{name: "nes level 29 and on", kind: NESGravity, level: 35, expected: 1},
+		{name: "20g from the start", kind: TwentyG, level: 1, expected: MaxGravity},
+		{name: "levels below 1 use level 1", kind: NESGravity, level: 0, expected: 1.0 / 48},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			g := NewGravityCurve(c.kind).At(c.level)
+			assert.InDelta(t, float64(c.expected), float64(g), 0.0001)
+		})
+	}
+}
+
+func Test_GravityCurve_Increases(t *testing.T) {
+	for _, kind := range []GravityKind{GuidelineGravity, NESGravity} {
+		c := NewGravityCurve(kind)
+		for i := 1; i < len(c.Levels); i++ {
+			assert.GreaterOrEqual(t, c.Levels[i], c.Levels[i-1], "%s level %d", kind, i+1)
+		}
+	}
+}
+
+func Test_ToGravityKind(t *testing.T) {
+	for _, k := range []GravityKind{GuidelineGravity, NESGravity, TwentyG} {
+		assert.Equal(t, k, ToGravityKind(k.String()))
+	}
+	assert.Equal(t, GuidelineGravity, ToGravityKind("unknown"))
+}
+
+func Test_ParseGravityCurve(t *testing.T) {
+	cases := []struct {
+		name     string
+		yaml     string
+		expected GravityCurve
+		err      bool
+	}{
+		{
+			name: "entries hold until the next level",
+			yaml: `
+name: slow
+levels:
+  - level: 1
+    frames: 60
+  - level: 3
+    g: 0.5
+`,
+			expected: GravityCurve{Name: "slow", Levels: []G{1.0 / 60, 1.0 / 60, 0.5}},
+		},
+		{
+			name: "levels are sorted and capped at 20G",
+			yaml: `
+name: fast
+levels:
+  - level: 2
+    g: 40
+  - level: 1
+    g: 1
+`,
+			expected: GravityCurve{Name: "fast", Levels: []G{1, MaxGravity}},
+		},
+		{
+			name: "first entry covers lower levels",
+			yaml: `
+levels:
+  - level: 2
+    g: 1
+`,
+			expected: GravityCurve{Levels: []G{1, 1}},
+		},
+		{name: "no levels", yaml: "name: empty", err: true},
+		{name: "not yaml", yaml: "levels: [", err: true},
+		{name: "level 0", yaml: "levels: [{level: 0, g: 1}]", err: true},
+		{name: "repeated level", yaml: "levels: [{level: 1, g: 1}, {level: 1, g: 2}]", err: true},
+		{name: "no gravity", yaml: "levels: [{level: 1}]", err: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			curve, err := ParseGravityCurve([]byte(c.yaml))
+			if c.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, c.expected, curve)
+		})
+	}
+}
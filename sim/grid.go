@@ -0,0 +1,134 @@
+package sim
+
+// cell is a single square of the Grid
+type cell struct {
+	filled bool
+	skin   int
+}
+
+// Grid is a dense row-major array of cells, cols wide and rows high,
+// with row 0 at the top of the visible board.  Above it are hidden
+// rows, numbered -1 up to -hidden, that hold any of the stack that
+// grows past the top.
+type Grid struct {
+	cols   int
+	rows   int
+	hidden int
+	cells  []cell
+}
+
+func NewGrid(cols, rows, hidden int) *Grid {
+	return &Grid{
+		cols:   cols,
+		rows:   rows,
+		hidden: hidden,
+		cells:  make([]cell, cols*(rows+hidden)),
+	}
+}
+
+// index is the position in cells of the column and row
+func (g *Grid) index(col, row int) int {
+	return (row+g.hidden)*g.cols + col
+}
+
+func (g *Grid) Cols() int {
+	return g.cols
+}
+
+func (g *Grid) Rows() int {
+	return g.rows
+}
+
+func (g *Grid) Hidden() int {
+	return g.hidden
+}
+
+func (g *Grid) reset() {
+	for i := range g.cells {
+		g.cells[i] = cell{}
+	}
+}
+
+// In reports if the column and row are inside the grid, including the
+// hidden rows
+func (g *Grid) In(col, row int) bool {
+	return col >= 0 && col < g.cols && row >= -g.hidden && row < g.rows
+}
+
+// Occupied reports if a block can not be placed at the column and
+// row.  The walls, the floor and the space above the hidden rows are
+// all occupied, so a piece never moves, kicks or locks outside of the
+// grid.
+func (g *Grid) Occupied(col, row int) bool {
+	if !g.In(col, row) {
+		return true
+	}
+	return g.cells[g.index(col, row)].filled
+}
+
+// Set fills the cell at the column and row, ignoring cells outside of
+// the grid, which a piece can not reach
+func (g *Grid) Set(col, row, skin int) {
+	if !g.In(col, row) {
+		return
+	}
+	g.cells[g.index(col, row)] = cell{filled: true, skin: skin}
+}
+
+func (g *Grid) rowFull(row int) bool {
+	for col := 0; col < g.cols; col++ {
+		if !g.cells[g.index(col, row)].filled {
+			return false
+		}
+	}
+	return true
+}
+
+// ClearFullRows removes every full row, shifting the rows above it
+// down, and reports the removed rows from top to bottom
+func (g *Grid) ClearFullRows() []int {
+	rows := []int{}
+	dst := g.rows - 1
+	for src := g.rows - 1; src >= -g.hidden; src-- {
+		if g.rowFull(src) {
+			rows = append([]int{src}, rows...)
+			continue
+		}
+		if dst != src {
+			d, s := g.index(0, dst), g.index(0, src)
+			copy(g.cells[d:d+g.cols], g.cells[s:s+g.cols])
+		}
+		dst--
+	}
+	for ; dst >= -g.hidden; dst-- {
+		for col := 0; col < g.cols; col++ {
+			g.cells[g.index(col, dst)] = cell{}
+		}
+	}
+	return rows
+}
+
+// Empty reports if no cells are filled
+func (g *Grid) Empty() bool {
+	for _, c := range g.cells {
+		if c.filled {
+			return false
+		}
+	}
+	return true
+}
+
+// Marks reports the filled cells, including those in the hidden rows,
+// ordered by row and then column
+func (g *Grid) Marks() []Mark {
+	marks := []Mark{}
+	for row := -g.hidden; row < g.rows; row++ {
+		for col := 0; col < g.cols; col++ {
+			c := g.cells[g.index(col, row)]
+			if c.filled {
+				marks = append(marks, Mark{Cell: Cell{col, row}, Skin: c.skin})
+			}
+		}
+	}
+	return marks
+}
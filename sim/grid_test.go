@@ -0,0 +1,75 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Grid_Occupied(t *testing.T) {
+	g := NewGrid(4, 3, 0)
+	g.Set(1, 2, 3)
+
+	assert.True(t, g.Occupied(-1, 0), "left wall")
+	assert.True(t, g.Occupied(4, 0), "right wall")
+	assert.True(t, g.Occupied(0, 3), "floor")
+	assert.True(t, g.Occupied(0, -2), "above the top is a ceiling")
+	assert.True(t, g.Occupied(1, 2))
+	assert.False(t, g.Occupied(2, 2))
+}
+
+func Test_Grid_ClearFullRows(t *testing.T) {
+	cases := []struct {
+		name     string
+		filled   []Cell
+		expected []int
+		marks    []Mark
+	}{
+		{
+			name:     "nothing to clear",
+			filled:   []Cell{{0, 2}},
+			expected: []int{},
+			marks:    []Mark{{Cell: Cell{0, 2}}},
+		},
+		{
+			name:     "bottom row shifts the stack down",
+			filled:   []Cell{{0, 2}, {1, 2}, {2, 2}, {1, 1}},
+			expected: []int{2},
+			marks:    []Mark{{Cell: Cell{1, 2}}},
+		},
+		{
+			name:     "split rows keep the row between them",
+			filled:   []Cell{{0, 0}, {1, 0}, {2, 0}, {1, 1}, {0, 2}, {1, 2}, {2, 2}},
+			expected: []int{0, 2},
+			marks:    []Mark{{Cell: Cell{1, 2}}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			g := NewGrid(3, 3, 0)
+			for _, f := range c.filled {
+				g.Set(f.Col, f.Row, 0)
+			}
+			assert.Equal(t, c.expected, g.ClearFullRows())
+			assert.Equal(t, c.marks, g.Marks())
+		})
+	}
+}
+
+func Test_Grid_Hidden(t *testing.T) {
+	g := NewGrid(3, 3, 2)
+	g.Set(0, -2, 1)
+	g.Set(1, -1, 2)
+
+	assert.True(t, g.In(0, -2))
+	assert.False(t, g.In(0, -3))
+	assert.True(t, g.Occupied(0, -2), "hidden rows hold blocks")
+	assert.True(t, g.Occupied(1, -3), "above the hidden rows is a ceiling")
+	assert.Equal(t, []Mark{{Cell{0, -2}, 1}, {Cell{1, -1}, 2}}, g.Marks())
+
+	for col := 0; col < 3; col++ {
+		g.Set(col, 2, 0)
+	}
+	assert.Equal(t, []int{2}, g.ClearFullRows())
+	assert.Equal(t, []Mark{{Cell{0, -1}, 1}, {Cell{1, 0}, 2}}, g.Marks(), "hidden rows shift down")
+}
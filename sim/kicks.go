@@ -0,0 +1,69 @@
+package sim
+
+import "github.com/lcaballero/ebiten-01/shapes"
+
+// kick is a rotation from one state to another
+type kick struct {
+	from Rotation
+	to   Rotation
+}
+
+// The SRS kick tests, tried in order, for the J, L, S, T and Z pieces.
+// As in the guideline tables x grows to the right and y grows up the
+// board, the opposite of rows.
+var jlstzKicks = map[kick]shapes.Vecs{
+	{R1, R2}: {{0, 0}, {-1, 0}, {-1, 1}, {0, -2}, {-1, -2}},
+	{R2, R1}: {{0, 0}, {1, 0}, {1, -1}, {0, 2}, {1, 2}},
+	{R2, R3}: {{0, 0}, {1, 0}, {1, -1}, {0, 2}, {1, 2}},
+	{R3, R2}: {{0, 0}, {-1, 0}, {-1, 1}, {0, -2}, {-1, -2}},
+	{R3, R4}: {{0, 0}, {1, 0}, {1, 1}, {0, -2}, {1, -2}},
+	{R4, R3}: {{0, 0}, {-1, 0}, {-1, -1}, {0, 2}, {-1, 2}},
+	{R4, R1}: {{0, 0}, {-1, 0}, {-1, -1}, {0, 2}, {-1, 2}},
+	{R1, R4}: {{0, 0}, {1, 0}, {1, 1}, {0, -2}, {1, -2}},
+}
+
+// The SRS kick tests, tried in order, for the I piece
+var iKicks = map[kick]shapes.Vecs{
+	{R1, R2}: {{0, 0}, {-2, 0}, {1, 0}, {-2, -1}, {1, 2}},
+	{R2, R1}: {{0, 0}, {2, 0}, {-1, 0}, {2, 1}, {-1, -2}},
+	{R2, R3}: {{0, 0}, {-1, 0}, {2, 0}, {-1, 2}, {2, -1}},
+	{R3, R2}: {{0, 0}, {1, 0}, {-2, 0}, {1, -2}, {-2, 1}},
+	{R3, R4}: {{0, 0}, {2, 0}, {-1, 0}, {2, 1}, {-1, -2}},
+	{R4, R3}: {{0, 0}, {-2, 0}, {1, 0}, {-2, -1}, {1, 2}},
+	{R4, R1}: {{0, 0}, {1, 0}, {-2, 0}, {1, -2}, {-2, 1}},
+	{R1, R4}: {{0, 0}, {-1, 0}, {2, 0}, {-1, 2}, {2, -1}},
+}
+
+// The kick tests for a half turn of any piece but the O, taken from
+// the SRS+ tables as SRS itself has no half turn
+var flipKicks = map[kick]shapes.Vecs{
+	{R1, R3}: {{0, 0}, {0, 1}, {1, 1}, {-1, 1}, {1, 0}, {-1, 0}},
+	{R3, R1}: {{0, 0}, {0, -1}, {-1, -1}, {1, -1}, {-1, 0}, {1, 0}},
+	{R2, R4}: {{0, 0}, {1, 0}, {1, 2}, {1, 1}, {0, 2}, {0, 1}},
+	{R4, R2}: {{0, 0}, {-1, 0}, {-1, 2}, {-1, 1}, {0, 2}, {0, 1}},
+}
+
+// noKicks is used for the O piece, and any rotation without a table,
+// which only ever rotates in place
+var noKicks = shapes.Vecs{{0, 0}}
+
+// kicksFor reports the offsets to try when rotating the Tetro between
+// the two rotation states
+func kicksFor(t Tetro, from, to Rotation) shapes.Vecs {
+	k := kick{from: from, to: to}
+	var tests shapes.Vecs
+	switch {
+	case t == O:
+		tests = noKicks
+	case from.Flip(t) == to:
+		tests = flipKicks[k]
+	case t == I:
+		tests = iKicks[k]
+	default:
+		tests = jlstzKicks[k]
+	}
+	if len(tests) == 0 {
+		return noKicks
+	}
+	return tests
+}
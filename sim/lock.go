@@ -0,0 +1,120 @@
+package sim
+
+import "time"
+
+// LockState is where a Tetromino is in the process of locking into
+// the board
+type LockState int
+
+const (
+	// Falling pieces have space below them
+	Falling LockState = 0
+	// Landed pieces are resting on the stack or floor and will lock
+	// once the lock delay runs out
+	Landed LockState = 1
+	// Locked pieces are part of the stack
+	Locked LockState = 2
+)
+
+func (s LockState) String() string {
+	switch s {
+	case Falling:
+		return "falling"
+	case Landed:
+		return "landed"
+	case Locked:
+		return "locked"
+	default:
+		return "unknown"
+	}
+}
+
+// ResetMode decides what restarts the lock delay of a landed piece
+type ResetMode int
+
+const (
+	// MoveReset restarts the delay on every successful move or rotation,
+	// up to the limit of resets for each new lowest row reached
+	MoveReset ResetMode = 0
+	// StepReset restarts the delay only when the piece falls a row
+	StepReset ResetMode = 1
+)
+
+func (m ResetMode) String() string {
+	switch m {
+	case MoveReset:
+		return "move-reset"
+	case StepReset:
+		return "step-reset"
+	default:
+		return "unknown"
+	}
+}
+
+// LockRules configures how long a landed piece can be moved before it
+// locks
+type LockRules struct {
+	Delay  time.Duration
+	Resets int
+	Mode   ResetMode
+}
+
+// DefaultLockRules is the guideline half second delay with up to 15
+// move resets
+func DefaultLockRules() LockRules {
+	return LockRules{
+		Delay:  500 * time.Millisecond,
+		Resets: 15,
+		Mode:   MoveReset,
+	}
+}
+
+// moved restarts the lock delay after the Tetromino successfully moved
+// or rotated
+func (t *Tetromino) moved(rules LockRules) {
+	if rules.Mode != MoveReset || t.resets >= rules.Resets {
+		return
+	}
+	if t.lock == Landed || t.lockTime > 0 {
+		t.resets++
+	}
+	t.lockTime = 0
+}
+
+// fell records the Tetromino moving down a row, where reaching a new
+// lowest row gives back all the resets
+func (t *Tetromino) fell() {
+	if t.row <= t.lowest {
+		return
+	}
+	t.lowest = t.row
+	t.resets = 0
+	t.lockTime = 0
+}
+
+// touch advances the lock state for a tick given whether the Tetromino
+// is resting on the stack or floor, and reports if it must now lock
+func (t *Tetromino) touch(grounded bool, elapsed time.Duration, rules LockRules) bool {
+	if t.lock == Locked {
+		return false
+	}
+	if !grounded {
+		t.lock = Falling
+		return false
+	}
+	t.lock = Landed
+	t.fall = 0
+	t.lockTime += elapsed
+	if rules.Mode == MoveReset && t.resets >= rules.Resets {
+		return true
+	}
+	// lock on the tick nearest to the delay
+	return t.lockTime+elapsed/2 >= rules.Delay
+}
+
+func (t *Tetromino) resetLock() {
+	t.lock = Falling
+	t.lockTime = 0
+	t.resets = 0
+	t.lowest = t.row
+}
@@ -0,0 +1,77 @@
+package sim
+
+import (
+	"math"
+	"time"
+)
+
+// Mode is the goal a game is played toward
+type Mode int
+
+const (
+	// Marathon goes on until the stack tops out
+	Marathon Mode = 1
+	// Sprint ends once SprintLines are cleared, racing the clock
+	Sprint Mode = 2
+	// Ultra ends after UltraTime, scoring as much as possible
+	Ultra Mode = 3
+)
+
+const (
+	SprintLines = 40
+	UltraTime   = 2 * time.Minute
+)
+
+func (m Mode) String() string {
+	switch m {
+	case Marathon:
+		return "marathon"
+	case Sprint:
+		return "sprint"
+	case Ultra:
+		return "ultra"
+	default:
+		return "unknown"
+	}
+}
+
+// Modes lists every Mode in the order they are offered
+var Modes = []Mode{Marathon, Sprint, Ultra}
+
+// ToMode converts the name of a mode, defaulting to Marathon
+func ToMode(s string) Mode {
+	for _, m := range Modes {
+		if m.String() == s {
+			return m
+		}
+	}
+	return Marathon
+}
+
+// reached reports if the goal of the mode is met by the score after
+// playing for the given ticks, rounding the time limit to whole ticks
+// as the tick is rarely an exact number of nanoseconds
+func (m Mode) reached(score ScoreBoard, played int, tick time.Duration) bool {
+	switch m {
+	case Sprint:
+		return score.Lines >= SprintLines
+	case Ultra:
+		return played >= int(math.Round(float64(UltraTime)/float64(tick)))
+	default:
+		return false
+	}
+}
+
+// Gravity is the curve the mode is played at unless another is chosen.
+// Marathon speeds up with each level, while Sprint and Ultra race the
+// clock at the gravity of level 1 so that every game is timed at the
+// same speed.
+func (m Mode) Gravity() GravityCurve {
+	curve := NewGravityCurve(GuidelineGravity)
+	switch m {
+	case Sprint, Ultra:
+		return GravityCurve{Name: m.String(), Levels: curve.Levels[:1]}
+	default:
+		return curve
+	}
+}
@@ -0,0 +1,72 @@
+package sim
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ToMode(t *testing.T) {
+	for _, m := range Modes {
+		assert.Equal(t, m, ToMode(m.String()))
+	}
+	assert.Equal(t, Marathon, ToMode("zen"))
+}
+
+func Test_Sim_Modes(t *testing.T) {
+	table := []struct {
+		name  string
+		mode  Mode
+		lines int
+		ticks int
+		over  bool
+	}{
+		{name: "marathon goes on", mode: Marathon, lines: 200, ticks: 1, over: false},
+		{name: "sprint short of its lines", mode: Sprint, lines: SprintLines - 1, ticks: 1, over: false},
+		{name: "sprint with its lines", mode: Sprint, lines: SprintLines, ticks: 1, over: true},
+		{name: "ultra short of its time", mode: Ultra, ticks: int(UltraTime/(time.Second/60)) - 1, over: false},
+		{name: "ultra out of time", mode: Ultra, ticks: int(UltraTime / (time.Second / 60)), over: true},
+	}
+	for _, row := range table {
+		t.Run(row.name, func(t *testing.T) {
+			cfg := DefaultConfig(1)
+			cfg.Mode = row.mode
+			cfg.Gravity = GravityCurve{Levels: []G{0}}
+			s := NewSim(cfg)
+			s.score.Lines = row.lines
+			evs := run(s, row.ticks)
+			assert.Equal(t, row.over, s.IsOver())
+			state := s.State()
+			assert.Equal(t, row.mode, state.Mode)
+			assert.Equal(t, time.Duration(row.ticks)*cfg.Tick, state.Time)
+			if row.over {
+				over := of(evs, GameOver)
+				assert.Len(t, over, 1)
+				assert.Equal(t, Finished, over[0].TopOut)
+			}
+		})
+	}
+}
+
+func Test_Mode_Gravity(t *testing.T) {
+	guideline := NewGravityCurve(GuidelineGravity)
+	assert.Equal(t, guideline, Marathon.Gravity())
+	assert.Equal(t, guideline, Mode(0).Gravity())
+	for _, m := range []Mode{Sprint, Ultra} {
+		curve := m.Gravity()
+		assert.Equal(t, m.String(), curve.Name)
+		assert.Equal(t, guideline.At(1), curve.At(1))
+		assert.Equal(t, guideline.At(1), curve.At(15), "stays at the gravity of level 1")
+	}
+}
+
+func Test_Sim_Played(t *testing.T) {
+	s := NewSim(DefaultConfig(1))
+	run(s, 10)
+	s.Step(Pause)
+	run(s, 10)
+	assert.Equal(t, 10*s.cfg.Tick, s.Played(), "paused ticks are not played")
+	s.Step(Restart)
+	assert.Equal(t, time.Duration(0), s.Played())
+}
@@ -0,0 +1,48 @@
+package sim
+
+import "sort"
+
+// actionOrder ranks the actions so that those arriving in the same
+// tick are always applied in the same order: restarting, pausing and
+// holding first, then moves before rotations so a piece can shift and
+// turn in one tick, and drops last so they act on the final position
+var actionOrder = map[Action]int{
+	Restart:    1,
+	Pause:      2,
+	Hold:       3,
+	ResetPiece: 4,
+	MoveLeft:   5,
+	MoveRight:  6,
+	RotateCW:   7,
+	RotateCCW:  8,
+	Rotate180:  9,
+	MoveDown:   10,
+	SoftDrop:   11,
+	HardDrop:   12,
+}
+
+// ActionQueue collects every action for a tick, from any number of
+// inputs, without dropping any
+type ActionQueue struct {
+	pending []Action
+}
+
+func NewActionQueue() *ActionQueue {
+	return &ActionQueue{}
+}
+
+// Push adds the actions for the next tick
+func (q *ActionQueue) Push(actions ...Action) {
+	q.pending = append(q.pending, actions...)
+}
+
+// Drain empties the queue reporting the actions by actionOrder, with
+// actions of the same kind kept in the order they were pushed
+func (q *ActionQueue) Drain() []Action {
+	actions := q.pending
+	q.pending = nil
+	sort.SliceStable(actions, func(i, j int) bool {
+		return actionOrder[actions[i]] < actionOrder[actions[j]]
+	})
+	return actions
+}
@@ -0,0 +1,36 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ActionQueue(t *testing.T) {
+	q := NewActionQueue()
+	assert.Empty(t, q.Drain())
+
+	q.Push(HardDrop, RotateCW)
+	q.Push(MoveLeft, MoveLeft)
+	q.Push(Hold)
+	assert.Equal(t, []Action{Hold, MoveLeft, MoveLeft, RotateCW, HardDrop}, q.Drain())
+	assert.Empty(t, q.Drain(), "drained")
+
+	for _, a := range actions {
+		assert.Contains(t, actionOrder, a, a.String())
+	}
+}
+
+func Test_ActionQueue_SameTick(t *testing.T) {
+	// rotating and moving in the same tick does the same thing however
+	// the inputs arrived
+	a, b := NewSim(DefaultConfig(1)), NewSim(DefaultConfig(1))
+	qa, qb := NewActionQueue(), NewActionQueue()
+	qa.Push(RotateCW, MoveLeft)
+	qb.Push(MoveLeft, RotateCW)
+	a.Step(qa.Drain()...)
+	b.Step(qb.Drain()...)
+	assert.Equal(t, a.State(), b.State())
+	assert.Equal(t, R2, a.State().Current.Rot)
+	assert.Equal(t, a.top().Col-1, a.State().Current.Col)
+}
@@ -0,0 +1,197 @@
+package sim
+
+import "github.com/lcaballero/ebiten-01/rand"
+
+// Randomizer decides the order that pieces are dealt
+type Randomizer interface {
+	Next() Tetro
+}
+
+// RandomizerKind names the randomizers that a game can be played with
+type RandomizerKind int
+
+const (
+	SevenBag    RandomizerKind = 1
+	FourteenBag RandomizerKind = 2
+	NES         RandomizerKind = 3
+	TGM         RandomizerKind = 4
+	Uniform     RandomizerKind = 5
+)
+
+func (k RandomizerKind) String() string {
+	switch k {
+	case SevenBag:
+		return "7-bag"
+	case FourteenBag:
+		return "14-bag"
+	case NES:
+		return "nes"
+	case TGM:
+		return "tgm"
+	case Uniform:
+		return "uniform"
+	default:
+		return "unknown"
+	}
+}
+
+// ToRandomizerKind converts the name of a randomizer, defaulting to
+// the 7-bag
+func ToRandomizerKind(s string) RandomizerKind {
+	switch s {
+	case "7-bag":
+		return SevenBag
+	case "14-bag":
+		return FourteenBag
+	case "nes":
+		return NES
+	case "tgm":
+		return TGM
+	case "uniform":
+		return Uniform
+	default:
+		return SevenBag
+	}
+}
+
+// NewRandomizer creates the kind of randomizer drawing from rnd
+func NewRandomizer(kind RandomizerKind, rnd rand.Rnd) Randomizer {
+	switch kind {
+	case FourteenBag:
+		return NewBag(rnd, 2)
+	case NES:
+		return NewNESRandomizer(rnd)
+	case TGM:
+		return NewTGMRandomizer(rnd)
+	case Uniform:
+		return NewUniformRandomizer(rnd)
+	default:
+		return NewBag(rnd, 1)
+	}
+}
+
+var tetros = []Tetro{I, O, T, S, Z, J, L}
+
+// UniformRandomizer picks each piece independently of the last
+type UniformRandomizer struct {
+	rnd rand.Rnd
+}
+
+func NewUniformRandomizer(rnd rand.Rnd) *UniformRandomizer {
+	return &UniformRandomizer{rnd: rnd}
+}
+
+func (u *UniformRandomizer) Next() Tetro {
+	return RandTetro(u.rnd)
+}
+
+// Bag deals every piece copies times in a shuffled order before
+// refilling, so there are never long droughts of any one piece
+type Bag struct {
+	rnd    rand.Rnd
+	copies int
+	bag    []Tetro
+}
+
+func NewBag(rnd rand.Rnd, copies int) *Bag {
+	return &Bag{rnd: rnd, copies: copies}
+}
+
+func (b *Bag) fill() {
+	b.bag = make([]Tetro, 0, len(tetros)*b.copies)
+	for i := 0; i < b.copies; i++ {
+		b.bag = append(b.bag, tetros...)
+	}
+	b.rnd.Shuffle(len(b.bag), func(j, k int) {
+		b.bag[j], b.bag[k] = b.bag[k], b.bag[j]
+	})
+}
+
+func (b *Bag) Next() Tetro {
+	if len(b.bag) == 0 {
+		b.fill()
+	}
+	t := b.bag[0]
+	b.bag = b.bag[1:]
+	return t
+}
+
+// NESRandomizer rolls once more when it picks the same piece as last
+// time, as the original NES game did
+type NESRandomizer struct {
+	rnd  rand.Rnd
+	last Tetro
+}
+
+func NewNESRandomizer(rnd rand.Rnd) *NESRandomizer {
+	return &NESRandomizer{rnd: rnd}
+}
+
+func (n *NESRandomizer) Next() Tetro {
+	// the roll has an 8th face that always rerolls
+	roll := n.rnd.Int(len(tetros) + 1)
+	if roll == len(tetros) || tetros[roll] == n.last {
+		roll = n.rnd.Int(len(tetros))
+	}
+	n.last = tetros[roll]
+	return n.last
+}
+
+// TGMRandomizer keeps a history of the last 4 pieces and rolls up to 4
+// times for a piece not in the history, as in Tetris The Grand Master
+type TGMRandomizer struct {
+	rnd     rand.Rnd
+	history []Tetro
+	first   bool
+}
+
+const tgmRolls = 4
+
+func NewTGMRandomizer(rnd rand.Rnd) *TGMRandomizer {
+	return &TGMRandomizer{
+		rnd:     rnd,
+		history: []Tetro{Z, Z, Z, Z},
+		first:   true,
+	}
+}
+
+func (g *TGMRandomizer) inHistory(t Tetro) bool {
+	for _, h := range g.history {
+		if h == t {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *TGMRandomizer) Next() Tetro {
+	var t Tetro
+	if g.first {
+		// the first piece is never one that starts with an overhang
+		starts := []Tetro{I, T, J, L}
+		t = starts[g.rnd.Int(len(starts))]
+		g.first = false
+	} else {
+		for i := 0; i < tgmRolls; i++ {
+			t = RandTetro(g.rnd)
+			if !g.inHistory(t) {
+				break
+			}
+		}
+	}
+	g.history = append(g.history[1:], t)
+	return t
+}
+
+// RepeatRandomizer deals the same piece every time
+type RepeatRandomizer struct {
+	tetro Tetro
+}
+
+func NewRepeatRandomizer(t Tetro) *RepeatRandomizer {
+	return &RepeatRandomizer{tetro: t}
+}
+
+func (r *RepeatRandomizer) Next() Tetro {
+	return r.tetro
+}
@@ -0,0 +1,98 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/lcaballero/ebiten-01/rand"
+	"github.com/stretchr/testify/assert"
+)
+
+// deal draws n pieces from the Randomizer
+func deal(r Randomizer, n int) []Tetro {
+	ts := make([]Tetro, n)
+	for i := range ts {
+		ts[i] = r.Next()
+	}
+	return ts
+}
+
+func counts(ts []Tetro) map[Tetro]int {
+	c := map[Tetro]int{}
+	for _, t := range ts {
+		c[t]++
+	}
+	return c
+}
+
+func Test_RandomizerKind(t *testing.T) {
+	kinds := []RandomizerKind{SevenBag, FourteenBag, NES, TGM, Uniform}
+	for _, k := range kinds {
+		assert.Equal(t, k, ToRandomizerKind(k.String()))
+	}
+	assert.Equal(t, SevenBag, ToRandomizerKind("other"))
+	assert.Equal(t, "unknown", RandomizerKind(42).String())
+}
+
+func Test_Bag(t *testing.T) {
+	cases := []struct {
+		name   string
+		copies int
+	}{
+		{name: "7-bag", copies: 1},
+		{name: "14-bag", copies: 2},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			b := NewBag(rand.NewRnd(33442), c.copies)
+			size := len(tetros) * c.copies
+			for i := 0; i < 50; i++ {
+				bag := counts(deal(b, size))
+				assert.Len(t, bag, len(tetros))
+				for _, n := range bag {
+					assert.Equal(t, c.copies, n)
+				}
+			}
+		})
+	}
+}
+
+func Test_NESRandomizer(t *testing.T) {
+	repeats := func(ts []Tetro) int {
+		n := 0
+		for i := 1; i < len(ts); i++ {
+			if ts[i] == ts[i-1] {
+				n++
+			}
+		}
+		return n
+	}
+	nes := deal(NewNESRandomizer(rand.NewRnd(33442)), 7000)
+	uniform := deal(NewUniformRandomizer(rand.NewRnd(33442)), 7000)
+
+	assert.Len(t, counts(nes), len(tetros))
+	assert.Less(t, repeats(nes), repeats(uniform)/2)
+}
+
+func Test_TGMRandomizer(t *testing.T) {
+	for seed := int64(0); seed < 50; seed++ {
+		first := NewTGMRandomizer(rand.NewRnd(seed)).Next()
+		assert.Contains(t, []Tetro{I, T, J, L}, first)
+	}
+	g := NewTGMRandomizer(rand.NewRnd(92219))
+	ts := deal(g, 7000)
+	assert.Len(t, counts(ts), len(tetros))
+	assert.Equal(t, ts[len(ts)-4:], g.history)
+}
+
+func Test_Randomizer_Seeded(t *testing.T) {
+	kinds := []RandomizerKind{SevenBag, FourteenBag, NES, TGM, Uniform}
+	for _, k := range kinds {
+		a := deal(NewRandomizer(k, rand.NewRnd(12231)), 100)
+		b := deal(NewRandomizer(k, rand.NewRnd(12231)), 100)
+		assert.Equal(t, a, b, k.String())
+	}
+}
+
+func Test_RepeatRandomizer(t *testing.T) {
+	assert.Equal(t, counts(deal(NewRepeatRandomizer(S), 10)), map[Tetro]int{S: 10})
+}
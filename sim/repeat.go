@@ -0,0 +1,124 @@
+package sim
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RepeatRules are the Delayed Auto Shift and Auto Repeat Rate of a
+// held input
+type RepeatRules struct {
+	DAS time.Duration // held this long before the move repeats
+	ARR time.Duration // between repeated moves, where 0 repeats at once up to the limit
+}
+
+// DefaultRepeatRules repeat after 10 frames and then every 2 frames
+func DefaultRepeatRules() RepeatRules {
+	return RepeatRules{
+		DAS: 167 * time.Millisecond,
+		ARR: 33 * time.Millisecond,
+	}
+}
+
+// ParseRepeat reads a DAS or ARR given in milliseconds, such as 167,
+// or in ticks with an f suffix, such as 10f for 10 frames
+func ParseRepeat(s string, tick time.Duration) (time.Duration, error) {
+	frames := strings.HasSuffix(s, "f")
+	n, err := strconv.Atoi(strings.TrimSuffix(s, "f"))
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("repeat %q is not milliseconds, or frames ending in f", s)
+	}
+	if frames {
+		return time.Duration(n) * tick, nil
+	}
+	return time.Duration(n) * time.Millisecond, nil
+}
+
+// Shifter turns held inputs, such as left and right, into the moves
+// for each tick.  A newly pressed input moves at once and then repeats
+// by the RepeatRules, and when several are held the one pressed last
+// wins.
+type Shifter struct {
+	rules RepeatRules
+	limit int      // moves made at once when ARR is 0
+	held  []Action // inputs held, in the order they were pressed
+	timer time.Duration
+	fired int // repeats made since DAS ran out
+}
+
+func NewShifter(rules RepeatRules, limit int) *Shifter {
+	return &Shifter{
+		rules: rules,
+		limit: limit,
+	}
+}
+
+// Update advances the Shifter by a tick given the inputs held during
+// it, and reports the moves to make
+func (s *Shifter) Update(elapsed time.Duration, pressed ...Action) []Action {
+	active := s.active()
+	s.release(pressed)
+	for _, a := range pressed {
+		if !s.holds(a) {
+			s.held = append(s.held, a)
+		}
+	}
+	next := s.active()
+	if next == 0 {
+		return nil
+	}
+	if next != active {
+		s.timer = 0
+		s.fired = 0
+		return []Action{next}
+	}
+
+	s.timer += elapsed
+	if s.timer < s.rules.DAS {
+		return nil
+	}
+	n := s.limit
+	if s.rules.ARR > 0 {
+		due := int((s.timer-s.rules.DAS)/s.rules.ARR) + 1
+		n = due - s.fired
+		s.fired = due
+	}
+	moves := make([]Action, n)
+	for i := range moves {
+		moves[i] = next
+	}
+	return moves
+}
+
+// active is the input pressed last of those still held
+func (s *Shifter) active() Action {
+	if len(s.held) == 0 {
+		return 0
+	}
+	return s.held[len(s.held)-1]
+}
+
+func (s *Shifter) holds(a Action) bool {
+	for _, h := range s.held {
+		if h == a {
+			return true
+		}
+	}
+	return false
+}
+
+// release forgets the inputs that are no longer pressed
+func (s *Shifter) release(pressed []Action) {
+	held := []Action{}
+	for _, h := range s.held {
+		for _, p := range pressed {
+			if h == p {
+				held = append(held, h)
+				break
+			}
+		}
+	}
+	s.held = held
+}
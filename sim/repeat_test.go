@@ -0,0 +1,100 @@
+package sim
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Shifter(t *testing.T) {
+	ms := time.Millisecond
+	L, R := MoveLeft, MoveRight
+	cases := []struct {
+		name     string
+		rules    RepeatRules
+		held     [][]Action // inputs held on each tick
+		expected [][]Action // moves made on each tick
+	}{
+		{
+			name:     "tap moves once",
+			rules:    RepeatRules{DAS: 50 * ms, ARR: 20 * ms},
+			held:     [][]Action{{L}, nil, {L}},
+			expected: [][]Action{{L}, nil, {L}},
+		},
+		{
+			name:     "held repeats after DAS at ARR",
+			rules:    RepeatRules{DAS: 50 * ms, ARR: 20 * ms},
+			held:     [][]Action{{L}, {L}, {L}, {L}, {L}, {L}, {L}, {L}},
+			expected: [][]Action{{L}, nil, nil, nil, nil, {L}, nil, {L}},
+		},
+		{
+			name:     "ARR shorter than a tick repeats more than once",
+			rules:    RepeatRules{DAS: 10 * ms, ARR: 5 * ms},
+			held:     [][]Action{{L}, {L}, {L}},
+			expected: [][]Action{{L}, {L}, {L, L}},
+		},
+		{
+			name:     "ARR of 0 slides to the wall",
+			rules:    RepeatRules{DAS: 10 * ms, ARR: 0},
+			held:     [][]Action{{R}, {R}, {R}},
+			expected: [][]Action{{R}, {R, R, R, R}, {R, R, R, R}},
+		},
+		{
+			name:     "last pressed direction wins",
+			rules:    RepeatRules{DAS: 50 * ms, ARR: 20 * ms},
+			held:     [][]Action{{L}, {L, R}, {R, L}, {L}},
+			expected: [][]Action{{L}, {R}, nil, {L}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := NewShifter(c.rules, 4)
+			for i, held := range c.held {
+				moves := s.Update(10*ms, held...)
+				if c.expected[i] == nil {
+					assert.Empty(t, moves, "tick %d", i)
+				} else {
+					assert.Equal(t, c.expected[i], moves, "tick %d", i)
+				}
+			}
+		})
+	}
+}
+
+func Test_ParseRepeat(t *testing.T) {
+	tick := time.Second / 60
+	cases := []struct {
+		name     string
+		value    string
+		expected time.Duration
+		err      bool
+	}{
+		{name: "milliseconds", value: "167", expected: 167 * time.Millisecond},
+		{name: "frames", value: "10f", expected: 10 * tick},
+		{name: "no frames", value: "0f", expected: 0},
+		{name: "negative", value: "-1", err: true},
+		{name: "other units", value: "2s", err: true},
+		{name: "only the suffix", value: "f", err: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d, err := ParseRepeat(c.value, tick)
+			if c.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, c.expected, d)
+		})
+	}
+}
+
+func Test_Sim_MoveDown(t *testing.T) {
+	s := NewSim(DefaultConfig(1))
+	row := s.State().Current.Row
+	evs := s.Step(MoveDown, MoveDown)
+	assert.Equal(t, row+2, s.State().Current.Row)
+	assert.Len(t, of(evs, PieceDropped), 2)
+	assert.Equal(t, 2, s.State().Score.Score)
+}
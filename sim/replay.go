@@ -0,0 +1,114 @@
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ReplayVersion is written to each replay, and only replays of the
+// same version can be played back
+const ReplayVersion = 1
+
+// Input is the actions applied at the start of a tick
+type Input struct {
+	Tick    int      `json:"tick"`
+	Actions []Action `json:"actions"`
+}
+
+// Replay is a recording of a game, holding the rules it was played
+// with, including the seed, and every input, so that stepping a new
+// Sim with the same inputs reproduces the game exactly
+type Replay struct {
+	Version int     `json:"version"`
+	Config  Config  `json:"config"`
+	Ticks   int     `json:"ticks"` // ticks played in all
+	Inputs  []Input `json:"inputs"`
+}
+
+// Recorder collects the inputs of a game into a Replay
+type Recorder struct {
+	replay Replay
+}
+
+func NewRecorder(cfg Config) *Recorder {
+	return &Recorder{
+		replay: Replay{Version: ReplayVersion, Config: cfg, Inputs: []Input{}},
+	}
+}
+
+// Record notes the actions applied at the tick, which must be called
+// for every tick, with or without actions, so the length of the game
+// is known
+func (r *Recorder) Record(tick int, actions []Action) {
+	r.replay.Ticks = tick + 1
+	if len(actions) == 0 {
+		return
+	}
+	acts := make([]Action, len(actions))
+	copy(acts, actions)
+	r.replay.Inputs = append(r.replay.Inputs, Input{Tick: tick, Actions: acts})
+}
+
+func (r *Recorder) Replay() Replay {
+	return r.replay
+}
+
+// Player feeds the inputs of a Replay back in tick by tick
+type Player struct {
+	replay Replay
+	next   int // index of the next input
+}
+
+func NewPlayer(r Replay) *Player {
+	return &Player{replay: r}
+}
+
+// Actions reports the recorded actions for the tick, which must be
+// asked for in order
+func (p *Player) Actions(tick int) []Action {
+	for p.next < len(p.replay.Inputs) && p.replay.Inputs[p.next].Tick < tick {
+		p.next++
+	}
+	if p.next < len(p.replay.Inputs) && p.replay.Inputs[p.next].Tick == tick {
+		p.next++
+		return p.replay.Inputs[p.next-1].Actions
+	}
+	return nil
+}
+
+// Done reports if the recording ended before the tick
+func (p *Player) Done(tick int) bool {
+	return tick >= p.replay.Ticks
+}
+
+// Play steps a new Sim through the whole Replay
+func (r Replay) Play() *Sim {
+	s := NewSim(r.Config)
+	p := NewPlayer(r)
+	for tick := 0; !p.Done(tick); tick++ {
+		s.Step(p.Actions(tick)...)
+	}
+	return s
+}
+
+// WriteReplay encodes the Replay as JSON
+func WriteReplay(w io.Writer, r Replay) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// ReadReplay decodes a Replay, failing on any version other than
+// ReplayVersion
+func ReadReplay(rd io.Reader) (Replay, error) {
+	r := Replay{}
+	err := json.NewDecoder(rd).Decode(&r)
+	if err != nil {
+		return Replay{}, fmt.Errorf("reading replay: %w", err)
+	}
+	if r.Version != ReplayVersion {
+		return Replay{}, fmt.Errorf("replay version %d is not supported, expected %d", r.Version, ReplayVersion)
+	}
+	return r, nil
+}
@@ -0,0 +1,91 @@
+package sim
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Replay(t *testing.T) {
+	cfg := DefaultConfig(92219)
+	cfg.Allow180 = true
+	s := NewSim(cfg)
+	rec := NewRecorder(cfg)
+	acts := [][]Action{{MoveLeft}, nil, {RotateCW, SoftDrop}, nil, {Rotate180}, {HardDrop}, {Hold}, nil, {MoveRight}}
+	for tick := 0; tick < 2000; tick++ {
+		a := acts[tick%len(acts)]
+		rec.Record(s.Tick(), a)
+		s.Step(a...)
+	}
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, WriteReplay(buf, rec.Replay()))
+	assert.Contains(t, buf.String(), `"hard-drop"`)
+
+	r, err := ReadReplay(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, 2000, r.Ticks)
+	assert.Equal(t, rec.Replay(), r)
+	assert.Equal(t, s.State(), r.Play().State())
+}
+
+func Test_Replay_Pause(t *testing.T) {
+	cfg := DefaultConfig(92219)
+	s := NewSim(cfg)
+	rec := NewRecorder(cfg)
+	for tick := 0; tick < 300; tick++ {
+		var a []Action
+		switch tick {
+		case 40, 200:
+			a = []Action{Pause}
+		case 100:
+			a = []Action{HardDrop}
+		}
+		rec.Record(s.Tick(), a)
+		s.Step(a...)
+	}
+
+	r := rec.Replay()
+	assert.Equal(t, []Action{Pause}, r.Inputs[0].Actions)
+	assert.Empty(t, r.Play().State().Marks, "the drop while paused is ignored")
+	assert.Equal(t, s.State(), r.Play().State())
+}
+
+func Test_ReadReplay_Errors(t *testing.T) {
+	cases := []struct {
+		name string
+		json string
+	}{
+		{name: "not json", json: "{"},
+		{name: "another version", json: `{"version": 2}`},
+		{name: "unknown action", json: `{"version": 1, "inputs": [{"tick": 0, "actions": ["fly"]}]}`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := ReadReplay(strings.NewReader(c.json))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func Test_Player(t *testing.T) {
+	p := NewPlayer(Replay{Ticks: 10, Inputs: []Input{
+		{Tick: 2, Actions: []Action{MoveLeft}},
+		{Tick: 5, Actions: []Action{HardDrop}},
+	}})
+	assert.Nil(t, p.Actions(0))
+	assert.Equal(t, []Action{MoveLeft}, p.Actions(2))
+	assert.Nil(t, p.Actions(3))
+	assert.Equal(t, []Action{HardDrop}, p.Actions(5))
+	assert.False(t, p.Done(9))
+	assert.True(t, p.Done(10))
+}
+
+func Test_ToAction(t *testing.T) {
+	for _, a := range actions {
+		assert.Equal(t, a, ToAction(a.String()))
+	}
+	assert.Equal(t, Action(0), ToAction("fly"))
+}
@@ -0,0 +1,127 @@
+package sim
+
+// LinesPerLevel is the number of cleared lines needed to reach the
+// next level
+const LinesPerLevel = 10
+
+// ScoreBoard records the current values that are shown on the board
+// during the game
+type ScoreBoard struct {
+	Score      int
+	Lines      int
+	Level      int
+	Combo      int  // pieces in a row that cleared lines
+	BackToBack bool // the last clear was a Tetris or T-spin
+}
+
+// Clear is the result of a piece locking, with the points it was
+// awarded and what they were awarded for
+type Clear struct {
+	Lines      int
+	Spin       Spin
+	Perfect    bool // the board is empty after the clear
+	Combo      int  // pieces in a row that cleared lines, including this one
+	BackToBack bool // the bonus for a Tetris or T-spin after another
+	Points     int
+	LevelUp    bool
+}
+
+// Difficult reports if the clear is a Tetris or a T-spin that cleared
+// lines, which keeps a back-to-back chain going
+func (c Clear) Difficult() bool {
+	return c.Lines >= 4 || (c.Spin != NoSpin && c.Lines > 0)
+}
+
+// Name describes the clear as shown on the HUD, or is empty when the
+// piece did not clear lines or spin
+func (c Clear) Name() string {
+	lines := []string{"", "single", "double", "triple", "tetris"}
+	n := lines[minInt(c.Lines, 4)]
+	switch {
+	case c.Spin == NoSpin:
+		return n
+	case n == "":
+		return c.Spin.String()
+	default:
+		return c.Spin.String() + " " + n
+	}
+}
+
+// linePoints are the points, multiplied by the level, for the lines
+// cleared by each kind of spin
+var linePoints = map[Spin][]int{
+	NoSpin:    {0, 100, 300, 500, 800},
+	MiniTSpin: {100, 200, 400, 400, 400},
+	TSpin:     {400, 800, 1200, 1600, 1600},
+}
+
+// perfectPoints are the bonus points, multiplied by the level, for
+// clearing the whole board
+var perfectPoints = []int{0, 800, 1200, 1800, 2000}
+
+const (
+	comboPoints           = 50
+	backToBackPerfect     = 3200
+	softDropPointsPerCell = 1
+	hardDropPointsPerCell = 2
+)
+
+// Clear scores a piece locking after clearing the given lines,
+// following the guideline values for line clears, T-spins, combos,
+// back-to-back and perfect clears.  Levels go up every LinesPerLevel
+// lines.
+func (s ScoreBoard) Clear(lines int, spin Spin, perfect bool) (ScoreBoard, Clear) {
+	c := Clear{Lines: lines, Spin: spin, Perfect: perfect && lines > 0}
+	n := minInt(lines, 4)
+	points := linePoints[spin][n] * s.Level
+
+	next := s
+	if lines == 0 {
+		next.Combo = 0
+	} else {
+		c.BackToBack = c.Difficult() && s.BackToBack
+		if c.BackToBack {
+			points += points / 2
+		}
+		next.BackToBack = c.Difficult()
+		next.Combo = s.Combo + 1
+		if next.Combo > 1 {
+			points += comboPoints * (next.Combo - 1) * s.Level
+		}
+	}
+	if c.Perfect {
+		bonus := perfectPoints[n]
+		if n == 4 && c.BackToBack {
+			bonus = backToBackPerfect
+		}
+		points += bonus * s.Level
+	}
+	next.Score += points
+	next.Lines += lines
+	next.Level = next.Lines/LinesPerLevel + 1
+	if next.Level < s.Level {
+		next.Level = s.Level
+	}
+
+	c.Combo = next.Combo
+	c.Points = points
+	c.LevelUp = next.Level > s.Level
+	return next, c
+}
+
+// Drop scores the cells a piece was soft or hard dropped by
+func (s ScoreBoard) Drop(cells int, hard bool) ScoreBoard {
+	per := softDropPointsPerCell
+	if hard {
+		per = hardDropPointsPerCell
+	}
+	s.Score += cells * per
+	return s
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,132 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_(t *testing.T) {
+	clear := func(lines int, spin Spin, perfect bool) func(ScoreBoard) ScoreBoard {
+		return func(s ScoreBoard) ScoreBoard {
+			next, _ := s.Clear(lines, spin, perfect)
+			return next
+		}
+	}
+	cases := []struct {
+		name     string
+		expected ScoreBoard
+		start    ScoreBoard
+		call     func(ScoreBoard) ScoreBoard
+	}{
+		{
+			name: "zero values equal each other",
+			call: func(s ScoreBoard) ScoreBoard {
+				return ScoreBoard{}
+			},
+		},
+		{
+			name:     "single",
+			start:    ScoreBoard{Score: 0, Level: 1, Lines: 0},
+			expected: ScoreBoard{Score: 100, Level: 1, Lines: 1, Combo: 1},
+			call:     clear(1, NoSpin, false),
+		},
+		{
+			name:     "tetris scores more than four singles",
+			start:    ScoreBoard{Score: 0, Level: 1, Lines: 0},
+			expected: ScoreBoard{Score: 800, Level: 1, Lines: 4, Combo: 1, BackToBack: true},
+			call:     clear(4, NoSpin, false),
+		},
+		{
+			name:     "back-to-back tetris is worth half again",
+			start:    ScoreBoard{Score: 800, Level: 1, Lines: 4, BackToBack: true},
+			expected: ScoreBoard{Score: 2000, Level: 1, Lines: 8, Combo: 1, BackToBack: true},
+			call:     clear(4, NoSpin, false),
+		},
+		{
+			name:     "single breaks the back-to-back chain",
+			start:    ScoreBoard{Score: 0, Level: 1, Lines: 4, BackToBack: true},
+			expected: ScoreBoard{Score: 100, Level: 1, Lines: 5, Combo: 1},
+			call:     clear(1, NoSpin, false),
+		},
+		{
+			name:     "combo adds points for each piece in a row",
+			start:    ScoreBoard{Score: 0, Level: 2, Lines: 12, Combo: 2},
+			expected: ScoreBoard{Score: 800, Level: 2, Lines: 14, Combo: 3},
+			call:     clear(2, NoSpin, false),
+		},
+		{
+			name:     "piece without lines ends the combo",
+			start:    ScoreBoard{Score: 500, Level: 1, Lines: 5, Combo: 3},
+			expected: ScoreBoard{Score: 500, Level: 1, Lines: 5},
+			call:     clear(0, NoSpin, false),
+		},
+		{
+			name:     "t-spin double by level",
+			start:    ScoreBoard{Score: 0, Level: 3, Lines: 20},
+			expected: ScoreBoard{Score: 3600, Level: 3, Lines: 22, Combo: 1, BackToBack: true},
+			call:     clear(2, TSpin, false),
+		},
+		{
+			name:     "mini t-spin without lines keeps the chain",
+			start:    ScoreBoard{Score: 0, Level: 1, Lines: 4, BackToBack: true},
+			expected: ScoreBoard{Score: 100, Level: 1, Lines: 4, BackToBack: true},
+			call:     clear(0, MiniTSpin, false),
+		},
+		{
+			name:     "level changes every 10 lines",
+			start:    ScoreBoard{Score: 0, Level: 1, Lines: 9},
+			expected: ScoreBoard{Score: 100, Level: 2, Lines: 10, Combo: 1},
+			call:     clear(1, NoSpin, false),
+		},
+		{
+			name:     "perfect clear tetris",
+			start:    ScoreBoard{Score: 0, Level: 1, Lines: 0},
+			expected: ScoreBoard{Score: 2800, Level: 1, Lines: 4, Combo: 1, BackToBack: true},
+			call:     clear(4, NoSpin, true),
+		},
+		{
+			name:     "soft drop scores a point a cell",
+			start:    ScoreBoard{Score: 5, Level: 3},
+			expected: ScoreBoard{Score: 15, Level: 3},
+			call: func(s ScoreBoard) ScoreBoard {
+				return s.Drop(10, false)
+			},
+		},
+		{
+			name:     "hard drop scores two points a cell",
+			start:    ScoreBoard{Score: 5, Level: 3},
+			expected: ScoreBoard{Score: 25, Level: 3},
+			call: func(s ScoreBoard) ScoreBoard {
+				return s.Drop(10, true)
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, c.call(c.start))
+		})
+	}
+}
+
+func Test_ScoreBoard_Clear(t *testing.T) {
+	start := ScoreBoard{Score: 0, Level: 1, Lines: 9, Combo: 1, BackToBack: true}
+	_, c := start.Clear(4, NoSpin, false)
+	assert.Equal(t, Clear{Lines: 4, Combo: 2, BackToBack: true, Points: 1250, LevelUp: true}, c)
+	assert.True(t, c.Difficult())
+	assert.Equal(t, "tetris", c.Name())
+
+	cases := []struct {
+		clear Clear
+		name  string
+	}{
+		{clear: Clear{}, name: ""},
+		{clear: Clear{Lines: 1}, name: "single"},
+		{clear: Clear{Lines: 3}, name: "triple"},
+		{clear: Clear{Spin: MiniTSpin}, name: "mini t-spin"},
+		{clear: Clear{Lines: 2, Spin: TSpin}, name: "t-spin double"},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.name, c.clear.Name())
+	}
+}
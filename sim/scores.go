@@ -0,0 +1,133 @@
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// ScoresVersion is the format of the high scores file, raised whenever
+// a change would misread older files
+const ScoresVersion = 1
+
+// MaxScores is the number of entries kept for each mode
+const MaxScores = 10
+
+// Entry is a finished game kept in the high scores
+type Entry struct {
+	Score    int           `json:"score"`
+	Lines    int           `json:"lines"`
+	Level    int           `json:"level"`
+	Duration time.Duration `json:"duration"`
+	Finished bool          `json:"finished"` // reached the goal of the mode
+	Date     time.Time     `json:"date"`
+	Seed     int64         `json:"seed"`
+	Replay   string        `json:"replay,omitempty"` // file the game was recorded to
+}
+
+// NewEntry describes the game at its end, played with the config
+func NewEntry(state State, cfg Config, date time.Time) Entry {
+	return Entry{
+		Score:    state.Score.Score,
+		Lines:    state.Score.Lines,
+		Level:    state.Score.Level,
+		Duration: state.Time,
+		Finished: state.TopOut == Finished,
+		Date:     date,
+		Seed:     cfg.Seed,
+	}
+}
+
+// Better reports if the entry ranks above the other in the mode, where
+// Sprint is a race to finish soonest and the other modes are for the
+// most points
+func (m Mode) Better(e, other Entry) bool {
+	switch {
+	case m == Sprint && e.Finished != other.Finished:
+		return e.Finished
+	case m == Sprint && e.Finished:
+		return e.Duration < other.Duration
+	case m == Sprint:
+		return e.Lines > other.Lines
+	default:
+		return e.Score > other.Score
+	}
+}
+
+// HighScores holds the best games of each mode, best first
+type HighScores struct {
+	Version int                `json:"version"`
+	Modes   map[string][]Entry `json:"modes"`
+}
+
+func NewHighScores() *HighScores {
+	return &HighScores{
+		Version: ScoresVersion,
+		Modes:   map[string][]Entry{},
+	}
+}
+
+// Top reports the entries of the mode, best first
+func (h *HighScores) Top(mode Mode) []Entry {
+	return h.Modes[mode.String()]
+}
+
+// Add ranks the entry among the scores of the mode, keeping MaxScores,
+// and reports its rank from 1, or 0 when it did not make the table.
+// Ties go to the entry that was there first.
+func (h *HighScores) Add(mode Mode, e Entry) int {
+	top := h.Top(mode)
+	rank := sort.Search(len(top), func(i int) bool { return mode.Better(e, top[i]) })
+	if rank >= MaxScores {
+		return 0
+	}
+	top = append(top, Entry{})
+	copy(top[rank+1:], top[rank:])
+	top[rank] = e
+	if len(top) > MaxScores {
+		top = top[:MaxScores]
+	}
+	h.Modes[mode.String()] = top
+	return rank + 1
+}
+
+// Prune keeps the best n entries of each mode, or of just the mode when
+// one is given, reporting the entries removed
+func (h *HighScores) Prune(n int, mode Mode) []Entry {
+	removed := []Entry{}
+	for _, m := range Modes {
+		top := h.Top(m)
+		if (mode != 0 && m != mode) || len(top) <= n {
+			continue
+		}
+		removed = append(removed, top[n:]...)
+		h.Modes[m.String()] = top[:n]
+	}
+	return removed
+}
+
+// WriteHighScores writes the scores as JSON
+func WriteHighScores(w io.Writer, h *HighScores) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(h)
+}
+
+// ReadHighScores decodes HighScores, failing on any version other than
+// ScoresVersion
+func ReadHighScores(r io.Reader) (*HighScores, error) {
+	h := NewHighScores()
+	err := json.NewDecoder(r).Decode(h)
+	if err != nil {
+		return nil, fmt.Errorf("reading high scores: %w", err)
+	}
+	if h.Version != ScoresVersion {
+		return nil, fmt.Errorf("high scores version %d is not supported, expected %d", h.Version, ScoresVersion)
+	}
+	if h.Modes == nil {
+		h.Modes = map[string][]Entry{}
+	}
+	return h, nil
+}
@@ -0,0 +1,108 @@
+package sim
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Mode_Better(t *testing.T) {
+	fast := Entry{Finished: true, Duration: time.Minute, Lines: 40}
+	slow := Entry{Finished: true, Duration: 2 * time.Minute, Lines: 40, Score: 9000}
+	short := Entry{Lines: 30, Score: 99999}
+	shorter := Entry{Lines: 20}
+	table := []struct {
+		name   string
+		mode   Mode
+		e      Entry
+		other  Entry
+		better bool
+	}{
+		{name: "sprint finished sooner", mode: Sprint, e: fast, other: slow, better: true},
+		{name: "sprint finished later", mode: Sprint, e: slow, other: fast, better: false},
+		{name: "sprint finished over not", mode: Sprint, e: slow, other: short, better: true},
+		{name: "sprint not over finished", mode: Sprint, e: short, other: slow, better: false},
+		{name: "sprint more lines", mode: Sprint, e: short, other: shorter, better: true},
+		{name: "marathon more points", mode: Marathon, e: short, other: slow, better: true},
+		{name: "ultra fewer points", mode: Ultra, e: fast, other: slow, better: false},
+		{name: "ties are not better", mode: Marathon, e: fast, other: fast, better: false},
+	}
+	for _, row := range table {
+		t.Run(row.name, func(t *testing.T) {
+			assert.Equal(t, row.better, row.mode.Better(row.e, row.other))
+		})
+	}
+}
+
+func Test_HighScores_Add(t *testing.T) {
+	h := NewHighScores()
+	assert.Equal(t, 1, h.Add(Marathon, Entry{Score: 100}))
+	assert.Equal(t, 1, h.Add(Marathon, Entry{Score: 300}))
+	assert.Equal(t, 2, h.Add(Marathon, Entry{Score: 200}))
+	assert.Equal(t, 3, h.Add(Marathon, Entry{Score: 200, Seed: 2}), "ties go to the first")
+	assert.Equal(t, 1, h.Add(Sprint, Entry{Finished: true, Duration: time.Minute}))
+
+	scores := []int{}
+	for _, e := range h.Top(Marathon) {
+		scores = append(scores, e.Score)
+	}
+	assert.Equal(t, []int{300, 200, 200, 100}, scores)
+	assert.Equal(t, int64(2), h.Top(Marathon)[2].Seed)
+	assert.Len(t, h.Top(Sprint), 1)
+	assert.Empty(t, h.Top(Ultra))
+
+	for i := 0; i < MaxScores; i++ {
+		h.Add(Marathon, Entry{Score: 1000})
+	}
+	assert.Len(t, h.Top(Marathon), MaxScores)
+	assert.Equal(t, 0, h.Add(Marathon, Entry{Score: 500}), "missed the table")
+	assert.Equal(t, 1, h.Add(Marathon, Entry{Score: 5000}))
+	assert.Len(t, h.Top(Marathon), MaxScores)
+}
+
+func Test_HighScores_Prune(t *testing.T) {
+	h := NewHighScores()
+	for i := 1; i <= 5; i++ {
+		h.Add(Marathon, Entry{Score: i * 100, Replay: "m"})
+		h.Add(Ultra, Entry{Score: i * 100, Replay: "u"})
+	}
+	removed := h.Prune(3, Ultra)
+	assert.Len(t, removed, 2)
+	assert.Equal(t, "u", removed[0].Replay)
+	assert.Len(t, h.Top(Ultra), 3)
+	assert.Len(t, h.Top(Marathon), 5)
+
+	removed = h.Prune(1, 0)
+	assert.Len(t, removed, 6)
+	assert.Equal(t, 500, h.Top(Marathon)[0].Score)
+	assert.Len(t, h.Top(Ultra), 1)
+}
+
+func Test_HighScores_ReadWrite(t *testing.T) {
+	cfg := DefaultConfig(7)
+	state := State{TopOut: Finished, Time: time.Minute, Score: ScoreBoard{Score: 900, Lines: 40, Level: 5}}
+	date := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	h := NewHighScores()
+	e := NewEntry(state, cfg, date)
+	e.Replay = "sprint.json"
+	h.Add(Sprint, e)
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, WriteHighScores(buf, h))
+	assert.Contains(t, buf.String(), `"sprint"`)
+	read, err := ReadHighScores(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, h, read)
+	assert.Equal(t, Entry{
+		Score: 900, Lines: 40, Level: 5, Duration: time.Minute, Finished: true,
+		Date: date, Seed: 7, Replay: "sprint.json",
+	}, read.Top(Sprint)[0])
+
+	_, err = ReadHighScores(strings.NewReader(`{"version": 9}`))
+	assert.Error(t, err)
+	_, err = ReadHighScores(strings.NewReader(`{`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,430 @@
+package sim
+
+import (
+	"time"
+
+	"github.com/lcaballero/ebiten-01/rand"
+	"github.com/lcaballero/ebiten-01/shapes"
+)
+
+// Config holds the rules for a game that are fixed for its duration
+type Config struct {
+	Seed        int64
+	Tick        time.Duration
+	Cols        int
+	Rows        int
+	Hidden      int // rows above the visible board that hold the stack
+	Skins       int
+	Randomizer  RandomizerKind
+	RepeatPiece Tetro // deal only this piece when set
+	Previews    int   // upcoming pieces shown, from 1 to 6
+	Allow180    bool
+	Mode        Mode
+
+	// SoftDropFactor multiplies gravity while soft dropping
+	SoftDropFactor float64
+
+	Gravity GravityCurve
+	Lock    LockRules
+}
+
+// DefaultConfig provides the rules of a standard game using the given
+// seed
+func DefaultConfig(seed int64) Config {
+	return Config{
+		Seed:       seed,
+		Tick:       time.Second / 60,
+		Cols:       10,
+		Rows:       20,
+		Hidden:     2,
+		Skins:      7,
+		Randomizer: SevenBag,
+		Previews:   5,
+		Mode:       Marathon,
+
+		SoftDropFactor: 20,
+		Gravity:        NewGravityCurve(GuidelineGravity),
+		Lock:           DefaultLockRules(),
+	}
+}
+
+// Sim advances a game in fixed ticks without a window, audio or a
+// keyboard, so that it can be driven by tests, bots and tools
+type Sim struct {
+	cfg     Config
+	rnd     rand.Rnd
+	deal    Randomizer
+	board   *Board
+	score   ScoreBoard
+	tick    int
+	played  int // ticks played, not counting while paused
+	paused  bool
+	soft    bool // soft dropping for the current tick
+	over    bool
+	topOut  TopOut
+	current *Tetromino
+	queue   []*Tetromino // upcoming pieces, next first
+	hold    *Tetromino
+	held    bool // hold was used for the current piece
+	events  []Event
+	bus     *Bus
+}
+
+const (
+	MinPreviews = 1
+	MaxPreviews = 6
+	MinCols     = 4 // wide enough for the I piece
+	MinRows     = 4
+	MinHidden   = 1 // holds the row pieces spawn in
+)
+
+// ClampPreviews limits the number of upcoming pieces shown to between
+// MinPreviews and MaxPreviews
+func ClampPreviews(n int) int {
+	if n < MinPreviews {
+		return MinPreviews
+	}
+	if n > MaxPreviews {
+		return MaxPreviews
+	}
+	return n
+}
+
+// ClampConfig limits the board to at least MinCols by MinRows, with
+// at least MinHidden rows above it, and the previews to their range
+func ClampConfig(cfg Config) Config {
+	cfg.Previews = ClampPreviews(cfg.Previews)
+	if cfg.Cols < MinCols {
+		cfg.Cols = MinCols
+	}
+	if cfg.Rows < MinRows {
+		cfg.Rows = MinRows
+	}
+	if cfg.Hidden < MinHidden {
+		cfg.Hidden = MinHidden
+	}
+	return cfg
+}
+
+func NewSim(cfg Config) *Sim {
+	cfg = ClampConfig(cfg)
+	rnd := rand.NewRnd(cfg.Seed)
+	var deal Randomizer = NewRandomizer(cfg.Randomizer, rnd)
+	if cfg.RepeatPiece != 0 {
+		deal = NewRepeatRandomizer(cfg.RepeatPiece)
+	}
+	s := &Sim{
+		cfg:   cfg,
+		rnd:   rnd,
+		deal:  deal,
+		board: NewBoard(cfg.Cols, cfg.Rows, cfg.Hidden),
+		score: ScoreBoard{Score: 0, Lines: 0, Level: 1},
+		bus:   NewBus(),
+	}
+	s.createStartPiece()
+	s.fillQueue()
+	return s
+}
+
+func (s *Sim) Config() Config {
+	return s.cfg
+}
+
+// Bus is where the events of each Step are published once the tick is
+// complete, for audio, the HUD, stats, replays and bots to subscribe
+// to.  Handlers must not call Step.
+func (s *Sim) Bus() *Bus {
+	return s.bus
+}
+
+// Tick is the number of the next tick to be stepped
+func (s *Sim) Tick() int {
+	return s.tick
+}
+
+func (s *Sim) Paused() bool {
+	return s.paused
+}
+
+// IsOver reports if the stack has topped out, after which only a
+// Restart action has any effect
+func (s *Sim) IsOver() bool {
+	return s.over
+}
+
+// top is the cell where the rotation box of new pieces enters the
+// board, centered and with the bottom of the piece on the top row
+func (s *Sim) top() Cell {
+	return Cell{Col: (s.cfg.Cols - 3) / 2, Row: -1}
+}
+
+// velocity is the speed pieces fall at, in cells per second, for the
+// current level
+func (s *Sim) velocity() shapes.Vec {
+	return shapes.Vec{0, s.cfg.Gravity.At(s.score.Level).CellsPerSecond()}
+}
+
+func (s *Sim) newPiece() *Tetromino {
+	return &Tetromino{
+		skin:     s.rnd.Int(s.cfg.Skins),
+		tetro:    s.deal.Next(),
+		rot:      R1,
+		velocity: s.velocity(),
+	}
+}
+
+func (s *Sim) createStartPiece() {
+	s.current = s.newPiece()
+	s.current.moveTo(s.top())
+	s.enter()
+}
+
+// fillQueue deals pieces until the queue holds all of the previews
+func (s *Sim) fillQueue() {
+	for len(s.queue) < s.cfg.Previews {
+		s.queue = append(s.queue, s.newPiece())
+	}
+}
+
+func (s *Sim) rotateInNextPiece() {
+	s.current = s.queue[0]
+	s.current.velocity = s.velocity()
+	s.current.moveTo(s.top())
+	s.queue = s.queue[1:]
+	s.fillQueue()
+	s.held = false
+	s.enter()
+}
+
+// enter reports the current piece entering the board and checks that
+// it does not overlap the stack
+func (s *Sim) enter() {
+	s.emit(Event{Kind: PieceSpawned, Tetro: s.current.tetro, Piece: s.current.piece()})
+	if s.board.BlockedOut(s.current) {
+		s.topOutWith(BlockOut)
+	}
+}
+
+// swapHold puts the current piece in the hold slot and brings in the
+// piece that was held, or the next piece when the slot is empty.  It
+// can only be used once for each piece that enters the board.
+func (s *Sim) swapHold() {
+	if s.held {
+		return
+	}
+	s.current.rot = R1
+	held := s.hold
+	s.hold = s.current
+	s.emit(Event{Kind: HoldUsed, Tetro: s.hold.tetro})
+	if held == nil {
+		s.rotateInNextPiece()
+	} else {
+		held.velocity = s.velocity()
+		held.moveTo(s.top())
+		s.current = held
+		s.enter()
+	}
+	s.held = true
+}
+
+// topOutWith ends the game, keeping the final score so it can be read
+// from the State until the game is restarted
+func (s *Sim) topOutWith(reason TopOut) {
+	s.over = true
+	s.topOut = reason
+	s.emit(Event{Kind: GameOver, Tetro: s.current.tetro, Score: s.score, TopOut: reason})
+}
+
+func (s *Sim) restart() {
+	s.over = false
+	s.topOut = 0
+	s.played = 0
+	s.score = ScoreBoard{Score: 0, Lines: 0, Level: 1}
+	s.hold = nil
+	s.held = false
+	s.board.reset()
+	s.queue = nil
+	s.createStartPiece()
+	s.fillQueue()
+}
+
+func (s *Sim) emit(ev Event) {
+	ev.Tick = s.tick
+	s.events = append(s.events, ev)
+}
+
+func (s *Sim) apply(a Action) {
+	switch a {
+	case MoveRight:
+		if s.board.CanGoRight(s.current) {
+			s.current.MoveRight()
+			s.current.moved(s.cfg.Lock)
+			s.emit(Event{Kind: PieceMoved, Tetro: s.current.tetro, Piece: s.current.piece()})
+		}
+	case MoveLeft:
+		if s.board.CanGoLeft(s.current) {
+			s.current.MoveLeft()
+			s.current.moved(s.cfg.Lock)
+			s.emit(Event{Kind: PieceMoved, Tetro: s.current.tetro, Piece: s.current.piece()})
+		}
+	case RotateCW:
+		_, ok := s.board.RotateRight(s.current)
+		s.rotated(ok)
+	case RotateCCW:
+		_, ok := s.board.RotateLeft(s.current)
+		s.rotated(ok)
+	case Rotate180:
+		if s.cfg.Allow180 {
+			_, ok := s.board.Rotate180(s.current)
+			s.rotated(ok)
+		}
+	case ResetPiece:
+		s.current.moveTo(s.top())
+	case Pause:
+		s.paused = !s.paused
+	case SoftDrop:
+		s.soft = true
+	case MoveDown:
+		if s.board.StepDown(s.current) {
+			s.score = s.score.Drop(1, false)
+			s.emit(Event{Kind: PieceDropped, Tetro: s.current.tetro, Cells: 1, Score: s.score})
+		}
+	case HardDrop:
+		rows := s.board.HardDrop(s.current)
+		s.board.Lock(s.current)
+		s.score = s.score.Drop(rows, true)
+		s.emit(Event{Kind: PieceDropped, Tetro: s.current.tetro, Cells: rows, Hard: true, Score: s.score})
+	case Hold:
+		s.swapHold()
+	case Restart:
+		s.restart()
+	}
+}
+
+// accepts reports if the action has any effect, where a paused game
+// only listens for Pause and Restart and a game that is over only for
+// Restart.  Once the piece has locked, such as by a hard drop earlier
+// in the tick, it no longer moves, drops or goes into hold.
+func (s *Sim) accepts(a Action) bool {
+	switch {
+	case a == Restart:
+		return true
+	case s.over:
+		return false
+	case s.paused:
+		return a == Pause
+	case s.current.lock == Locked:
+		return a == Pause
+	default:
+		return true
+	}
+}
+
+// rotated restarts the lock delay when a rotation succeeded
+func (s *Sim) rotated(ok bool) {
+	if ok {
+		s.current.moved(s.cfg.Lock)
+		s.emit(Event{Kind: PieceRotated, Tetro: s.current.tetro, Piece: s.current.piece()})
+	}
+}
+
+// Step applies the actions in order and then advances the game by a
+// single tick, reporting the events that happened along the way and
+// publishing them on the Bus
+func (s *Sim) Step(actions ...Action) []Event {
+	s.soft = false
+	for _, a := range actions {
+		if s.accepts(a) {
+			s.apply(a)
+		}
+	}
+	if s.over {
+		return s.done()
+	}
+	if !s.paused {
+		s.played++
+		dt := float64(s.cfg.Tick) / float64(time.Second)
+		factor := 1.0
+		if s.soft {
+			factor = s.cfg.SoftDropFactor
+		}
+		s.current.Update(s.cfg.Tick, dt, factor)
+		rows := s.board.Drop(s.current)
+		if s.soft && rows > 0 {
+			s.score = s.score.Drop(rows, false)
+			s.emit(Event{Kind: PieceDropped, Tetro: s.current.tetro, Cells: rows, Score: s.score})
+		}
+		s.board.CheckBounds(s.current, s.cfg.Tick, s.cfg.Lock)
+	}
+	if s.current.lock == Locked {
+		locked := s.current.tetro
+		if s.board.LockedOut(s.current) {
+			s.emit(Event{Kind: PieceLocked, Tetro: locked, Score: s.score})
+			s.topOutWith(LockOut)
+			return s.done()
+		}
+		cleared := s.board.ClearLines(s.current)
+		var clear Clear
+		s.score, clear = s.score.Clear(len(cleared.Rows), cleared.Spin, cleared.Perfect)
+		s.emit(Event{Kind: PieceLocked, Tetro: locked, Score: s.score, Clear: clear})
+		if len(cleared.Rows) > 0 {
+			s.emit(Event{Kind: LinesCleared, Tetro: locked, Rows: cleared.Rows, Score: s.score, Clear: clear})
+		}
+		if clear.LevelUp {
+			s.emit(Event{Kind: LevelUp, Tetro: locked, Score: s.score})
+		}
+		s.rotateInNextPiece()
+	}
+	if !s.over && s.cfg.Mode.reached(s.score, s.played, s.cfg.Tick) {
+		s.topOutWith(Finished)
+	}
+	return s.done()
+}
+
+// Played is how long the game has been played, not counting the time
+// it was paused
+func (s *Sim) Played() time.Duration {
+	return time.Duration(s.played) * s.cfg.Tick
+}
+
+// done ends the tick, publishing the events emitted since the last
+// one, including those from creating the Sim, and reporting them
+func (s *Sim) done() []Event {
+	s.tick++
+	events := s.events
+	s.events = nil
+	s.bus.Publish(events...)
+	return events
+}
+
+// State reports a snapshot of the game at the current tick
+func (s *Sim) State() State {
+	var hold *Piece
+	if s.hold != nil {
+		p := s.hold.piece()
+		hold = &p
+	}
+	queue := make([]Piece, len(s.queue))
+	for i, t := range s.queue {
+		queue[i] = t.piece()
+	}
+	ghost := s.current.piece()
+	ghost.Cell = s.board.Landing(s.current)
+	return State{
+		Tick:    s.tick,
+		Paused:  s.paused,
+		Over:    s.over,
+		TopOut:  s.topOut,
+		Mode:    s.cfg.Mode,
+		Time:    s.Played(),
+		Score:   s.score,
+		Cols:    s.cfg.Cols,
+		Rows:    s.cfg.Rows,
+		Current: s.current.piece(),
+		Ghost:   ghost,
+		Queue:   queue,
+		Hold:    hold,
+		Held:    s.held,
+		Marks:   s.board.Marks(),
+	}
+}
@@ -0,0 +1,460 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// run steps the Sim for n ticks applying the actions on the first
+// tick and collecting every event that was reported
+func run(s *Sim, n int, actions ...Action) []Event {
+	events := []Event{}
+	for i := 0; i < n; i++ {
+		events = append(events, s.Step(actions...)...)
+		actions = nil
+	}
+	return events
+}
+
+// of filters the events to those of the given kind
+func of(evs []Event, kind EventKind) []Event {
+	found := []Event{}
+	for _, ev := range evs {
+		if ev.Kind == kind {
+			found = append(found, ev)
+		}
+	}
+	return found
+}
+
+// kinds lists the kind of each event in order
+func kinds(evs []Event) []EventKind {
+	ks := []EventKind{}
+	for _, ev := range evs {
+		ks = append(ks, ev.Kind)
+	}
+	return ks
+}
+
+func Test_NewSim(t *testing.T) {
+	s := NewSim(DefaultConfig(12231))
+	st := s.State()
+
+	assert.Equal(t, 0, st.Tick)
+	assert.False(t, st.Paused)
+	assert.Equal(t, ScoreBoard{Score: 0, Lines: 0, Level: 1}, st.Score)
+	assert.Empty(t, st.Marks)
+	assert.Len(t, st.Current.Cells(), 4)
+	assert.Len(t, st.Queue, 5)
+	assert.Len(t, st.Queue[0].Cells(), 4)
+	assert.Equal(t, s.top(), st.Current.Cell)
+	assert.Equal(t, st.Current.Shape, st.Ghost.Shape)
+	assert.Greater(t, st.Ghost.Row, 15)
+	assert.Equal(t, 10, st.Cols)
+	assert.Equal(t, 20, st.Rows)
+}
+
+func Test_Sim_Dimensions(t *testing.T) {
+	cases := []struct {
+		name string
+		cols int
+		rows int
+		top  Cell
+	}{
+		{name: "narrow", cols: 4, rows: 20, top: Cell{Col: 0, Row: -1}},
+		{name: "wide", cols: 12, rows: 20, top: Cell{Col: 4, Row: -1}},
+		{name: "tall", cols: 10, rows: 40, top: Cell{Col: 3, Row: -1}},
+	}
+	narrow := DefaultConfig(1)
+	narrow.Cols = 1
+	assert.Equal(t, MinCols, NewSim(narrow).Config().Cols, "too narrow")
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := DefaultConfig(1)
+			cfg.Cols, cfg.Rows = c.cols, c.rows
+			s := NewSim(cfg)
+			assert.Equal(t, c.top, s.top())
+
+			evs := run(s, 1, HardDrop)
+			assert.Equal(t, c.rows-1, of(evs, PieceDropped)[0].Cells)
+			for _, m := range s.State().Marks {
+				assert.Less(t, m.Col, c.cols)
+				assert.Less(t, m.Row, c.rows)
+			}
+		})
+	}
+}
+
+func Test_Sim_Step(t *testing.T) {
+	cases := []struct {
+		name  string
+		ticks int
+		acts  []Action
+		check func(*testing.T, State, []Event)
+	}{
+		{
+			name:  "advances a tick",
+			ticks: 2,
+			check: func(t *testing.T, st State, evs []Event) {
+				assert.Equal(t, 2, st.Tick)
+				assert.Equal(t, []EventKind{PieceSpawned}, kinds(evs), "the first piece")
+			},
+		},
+		{
+			name:  "pause stops gravity",
+			ticks: 10,
+			acts:  []Action{Pause},
+			check: func(t *testing.T, st State, evs []Event) {
+				assert.True(t, st.Paused)
+				assert.Equal(t, NewSim(DefaultConfig(1)).top(), st.Current.Cell)
+			},
+		},
+		{
+			name:  "moving left shifts one cell",
+			ticks: 1,
+			acts:  []Action{MoveLeft},
+			check: func(t *testing.T, st State, evs []Event) {
+				assert.Equal(t, NewSim(DefaultConfig(1)).top().Col-1, st.Current.Col)
+			},
+		},
+		{
+			name:  "hard dropped piece locks on the floor",
+			ticks: 1,
+			acts:  []Action{HardDrop},
+			check: func(t *testing.T, st State, evs []Event) {
+				assert.Len(t, st.Marks, 4)
+				assert.Equal(t, []EventKind{PieceSpawned, PieceDropped, PieceLocked, PieceSpawned}, kinds(evs))
+				assert.True(t, evs[1].Hard)
+				assert.Greater(t, evs[1].Cells, 15)
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := NewSim(DefaultConfig(1))
+			evs := run(s, c.ticks, c.acts...)
+			c.check(t, s.State(), evs)
+		})
+	}
+}
+
+func Test_Sim_SoftDrop(t *testing.T) {
+	normal := NewSim(DefaultConfig(1))
+	soft := NewSim(DefaultConfig(1))
+	cells := 0
+	for i := 0; i < 30; i++ {
+		normal.Step()
+		for _, ev := range soft.Step(SoftDrop) {
+			if ev.Kind == PieceDropped {
+				assert.False(t, ev.Hard)
+				cells += ev.Cells
+			}
+		}
+	}
+	assert.Equal(t, normal.top(), normal.State().Current.Cell)
+	assert.InDelta(t, 10, cells, 1)
+	assert.Equal(t, soft.top().Row+cells, soft.State().Current.Row)
+
+	// releasing the soft drop returns to normal gravity
+	row := soft.State().Current.Row
+	run(soft, 30)
+	assert.LessOrEqual(t, soft.State().Current.Row-row, 1)
+}
+
+func Test_Sim_Gravity(t *testing.T) {
+	cfg := DefaultConfig(1)
+	cfg.Gravity = NewGravityCurve(TwentyG)
+	s := NewSim(cfg)
+	s.Step()
+	st := s.State()
+	assert.Equal(t, st.Ghost.Cell, st.Current.Cell, "20G lands at once")
+
+	s.Step(MoveLeft)
+	st = s.State()
+	assert.Equal(t, st.Ghost.Cell, st.Current.Cell, "and stays down after moving")
+
+	cfg.Gravity = GravityCurve{Levels: []G{0.5}}
+	s = NewSim(cfg)
+	run(s, 5)
+	assert.Equal(t, s.top().Row+2, s.State().Current.Row, "half a row a frame")
+}
+
+func Test_Sim_Scoring(t *testing.T) {
+	cfg := DefaultConfig(1)
+	cfg.RepeatPiece = I
+	s := NewSim(cfg)
+	s.current.tetro = I
+	for _, col := range []int{0, 1, 2, 7, 8, 9} {
+		s.board.grid.Set(col, 19, 0)
+	}
+
+	evs := s.Step(HardDrop)
+	assert.Equal(t, 38, of(evs, PieceDropped)[0].Score.Score, "two points for each of the 19 rows")
+	assert.Equal(t, Clear{Lines: 1, Perfect: true, Combo: 1, Points: 900}, of(evs, LinesCleared)[0].Clear)
+	assert.Equal(t, ScoreBoard{Score: 938, Lines: 1, Level: 1, Combo: 1}, s.State().Score)
+}
+
+func Test_Sim_Events(t *testing.T) {
+	cfg := DefaultConfig(1)
+	cfg.RepeatPiece = I
+	s := NewSim(cfg)
+	s.current.tetro = I
+	published := []Event{}
+	s.Bus().Subscribe(func(ev Event) { published = append(published, ev) })
+
+	evs := s.Step(MoveLeft, RotateCW, Hold)
+	assert.Equal(t, []EventKind{PieceSpawned, PieceMoved, PieceRotated, HoldUsed, PieceSpawned}, kinds(evs))
+	assert.Equal(t, evs, published, "published once the tick is done")
+	assert.Equal(t, R2, evs[2].Piece.Rot)
+	assert.Equal(t, I, evs[3].Tetro)
+
+	// clearing the tenth line levels up
+	s.score.Lines = 9
+	for _, col := range []int{0, 1, 2, 7, 8, 9} {
+		s.board.grid.Set(col, 19, 0)
+	}
+	evs = s.Step(HardDrop)
+	assert.Equal(t, []EventKind{PieceDropped, PieceLocked, LinesCleared, LevelUp, PieceSpawned}, kinds(evs))
+	assert.Equal(t, 2, evs[3].Score.Level)
+}
+
+func Test_Sim_LockDelay(t *testing.T) {
+	ticks := func(s *Sim) int {
+		for i := 1; i < 600; i++ {
+			for _, ev := range s.Step() {
+				if ev.Kind == PieceLocked {
+					return i
+				}
+			}
+		}
+		return -1
+	}
+	landed := func(cfg Config) *Sim {
+		cfg.RepeatPiece = O
+		s := NewSim(cfg)
+		s.current.tetro = O
+		s.current.moveTo(Cell{Col: 3, Row: 18})
+		return s
+	}
+
+	s := landed(DefaultConfig(1))
+	assert.Equal(t, 30, ticks(s), "locks after half a second at 60 ticks")
+
+	s = landed(DefaultConfig(1))
+	run(s, 20)
+	s.Step(MoveLeft)
+	assert.Equal(t, Landed, s.current.lock)
+	assert.Equal(t, 1, s.current.resets)
+	assert.Equal(t, 29, ticks(s), "a move restarts the delay")
+
+	s = landed(DefaultConfig(1))
+	moves := []Action{MoveLeft, MoveRight}
+	locked := -1
+	for i := 1; i < 60 && locked < 0; i++ {
+		for _, ev := range s.Step(moves[i%2]) {
+			if ev.Kind == PieceLocked {
+				locked = i
+			}
+		}
+	}
+	assert.Equal(t, 16, locked, "locks once the 15 resets run out")
+
+	cfg := DefaultConfig(1)
+	cfg.Lock.Mode = StepReset
+	s = landed(cfg)
+	run(s, 20)
+	s.Step(MoveLeft)
+	assert.Equal(t, 9, ticks(s), "moves do not restart the delay")
+
+	s = landed(DefaultConfig(1))
+	evs := s.Step(HardDrop)
+	assert.Len(t, of(evs, PieceLocked), 1, "hard drop locks at once")
+
+	s = landed(DefaultConfig(1))
+	s.Step(Pause)
+	run(s, 60)
+	assert.Empty(t, s.State().Marks, "paused games do not lock")
+}
+
+func Test_Sim_Hold(t *testing.T) {
+	s := NewSim(DefaultConfig(92219))
+	first := s.State()
+	assert.Nil(t, first.Hold)
+
+	s.Step(MoveLeft, RotateCW, Hold)
+	st := s.State()
+	assert.True(t, st.Held)
+	assert.Equal(t, first.Current.Tetro, st.Hold.Tetro)
+	assert.Equal(t, R1, st.Hold.Rot)
+	assert.Equal(t, first.Queue[0].Tetro, st.Current.Tetro)
+
+	// only once per piece
+	s.Step(Hold)
+	assert.Equal(t, st.Current.Tetro, s.State().Current.Tetro)
+
+	// the next piece may swap with the held one
+	s.Step(HardDrop)
+	assert.False(t, s.State().Held)
+	next := s.State().Current.Tetro
+	s.Step(Hold)
+	st = s.State()
+	assert.Equal(t, first.Current.Tetro, st.Current.Tetro)
+	assert.Equal(t, s.top(), st.Current.Cell)
+	assert.Equal(t, next, st.Hold.Tetro)
+}
+
+func Test_Sim_HoldKeepsUpWithLevel(t *testing.T) {
+	s := NewSim(DefaultConfig(92219))
+	slow := s.velocity()
+	s.Step(Hold)
+	s.score.Level = 10
+	s.Step(HardDrop)
+	s.Step(Hold)
+	assert.NotEqual(t, slow, s.velocity())
+	assert.Equal(t, s.velocity(), s.current.velocity, "falls at the gravity of the level it comes back at")
+}
+
+func Test_Sim_HardDropThenHold(t *testing.T) {
+	s := NewSim(DefaultConfig(92219))
+	first := s.State()
+	evs := s.Step(HardDrop, Hold, HardDrop)
+	st := s.State()
+	assert.Nil(t, st.Hold, "the locked piece is not held")
+	assert.False(t, st.Held)
+	assert.Len(t, st.Marks, 4)
+	assert.Equal(t, first.Queue[0].Tetro, st.Current.Tetro)
+	assert.Len(t, of(evs, PieceDropped), 1, "the second hard drop does nothing")
+	assert.Empty(t, of(evs, HoldUsed))
+}
+
+func Test_Sim_Rotate180(t *testing.T) {
+	cfg := DefaultConfig(1)
+	cfg.RepeatPiece = T
+	s := NewSim(cfg)
+	s.current.tetro = T
+
+	s.Step(Rotate180)
+	assert.Equal(t, R1, s.State().Current.Rot)
+
+	cfg.Allow180 = true
+	s = NewSim(cfg)
+	s.current.tetro = T
+	s.Step(Rotate180)
+	assert.Equal(t, R3, s.State().Current.Rot)
+}
+
+func Test_Sim_Deterministic(t *testing.T) {
+	a := NewSim(DefaultConfig(92219))
+	b := NewSim(DefaultConfig(92219))
+	acts := []Action{SoftDrop, MoveLeft, RotateCW, HardDrop, MoveRight, RotateCCW}
+	for i := 0; i < 3000; i++ {
+		act := acts[i%len(acts)]
+		assert.Equal(t, a.Step(act), b.Step(act))
+	}
+	assert.Equal(t, a.State(), b.State())
+	assert.NotEmpty(t, a.State().Marks)
+}
+
+func Test_Sim_Queue(t *testing.T) {
+	cases := []struct {
+		name     string
+		previews int
+		expected int
+	}{
+		{name: "no previews shows one", previews: 0, expected: 1},
+		{name: "three previews", previews: 3, expected: 3},
+		{name: "at most six previews", previews: 9, expected: 6},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := DefaultConfig(12231)
+			cfg.Previews = c.previews
+			s := NewSim(cfg)
+			queue := s.State().Queue
+			assert.Len(t, queue, c.expected)
+
+			s.Step(HardDrop)
+			st := s.State()
+			assert.Equal(t, queue[0].Tetro, st.Current.Tetro)
+			assert.Len(t, st.Queue, c.expected)
+			assert.Equal(t, queue[1:], st.Queue[:c.expected-1])
+		})
+	}
+}
+
+func Test_Sim_LockInHiddenRows(t *testing.T) {
+	cfg := DefaultConfig(1)
+	cfg.RepeatPiece = O
+	cfg.Hidden = 0
+	s := NewSim(cfg)
+	assert.Equal(t, MinHidden, s.Config().Hidden, "the spawn row stays in the grid")
+	s.current.tetro = O
+	cells := s.current.cells()
+	for _, c := range cells {
+		s.board.grid.Set(c.Col, 1, 0)
+	}
+
+	run(s, 60)
+	marks := map[Cell]bool{}
+	for _, m := range s.State().Marks {
+		marks[m.Cell] = true
+	}
+	for _, c := range cells {
+		assert.True(t, marks[c], "%v is kept", c)
+	}
+	assert.Len(t, marks, len(cells)+2)
+	assert.Equal(t, BlockOut, s.State().TopOut)
+}
+
+func Test_Sim_TopOut(t *testing.T) {
+	cases := []struct {
+		name   string
+		setup  func(*Sim)
+		topOut TopOut
+	}{
+		{
+			name: "next piece spawning on the stack blocks out",
+			setup: func(s *Sim) {
+				for col := 3; col <= 6; col++ {
+					s.board.grid.Set(col, 1, 0)
+				}
+			},
+			topOut: BlockOut,
+		},
+		{
+			name: "piece locking above the board locks out",
+			setup: func(s *Sim) {
+				s.board.grid.Set(5, 0, 0)
+				s.board.grid.Set(6, 0, 0)
+				s.current.moveTo(Cell{Col: 4, Row: -2})
+			},
+			topOut: LockOut,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := DefaultConfig(1)
+			cfg.RepeatPiece = O
+			s := NewSim(cfg)
+			s.current.tetro = O
+			c.setup(s)
+
+			evs := run(s, 60)
+			last := evs[len(evs)-1]
+			assert.Equal(t, GameOver, last.Kind)
+			assert.Equal(t, c.topOut, last.TopOut)
+
+			st := s.State()
+			assert.True(t, st.Over)
+			assert.Equal(t, c.topOut, st.TopOut)
+
+			assert.Empty(t, s.Step(MoveLeft, HardDrop))
+			assert.Equal(t, st.Current, s.State().Current)
+
+			s.Step(Restart)
+			assert.False(t, s.IsOver())
+			assert.Empty(t, s.State().Marks)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package sim
+
+// Spin is how the piece was last turned into place before locking
+type Spin int
+
+const (
+	NoSpin    Spin = 0
+	MiniTSpin Spin = 1
+	TSpin     Spin = 2
+)
+
+func (s Spin) String() string {
+	switch s {
+	case MiniTSpin:
+		return "mini t-spin"
+	case TSpin:
+		return "t-spin"
+	default:
+		return "none"
+	}
+}
+
+// tCorners are the corners of the T's 3x3 rotation box, with the two
+// on the side the T points toward first
+var tCorners = map[Rotation][4]Cell{
+	R1: {{0, 0}, {2, 0}, {0, 2}, {2, 2}},
+	R2: {{2, 0}, {2, 2}, {0, 0}, {0, 2}},
+	R3: {{0, 2}, {2, 2}, {0, 0}, {2, 0}},
+	R4: {{0, 0}, {0, 2}, {2, 0}, {2, 2}},
+}
+
+// tstKick is the last kick test of a quarter turn, which lifts the T
+// two rows into a T-spin triple slot and always counts as a full
+// T-spin.  The same test of a half turn is an ordinary kick.
+const tstKick = 4
+
+// Spin reports if the Tetromino is a T that was rotated into place,
+// by the 3-corner rule: when at least three corners of its rotation
+// box are occupied it is a T-spin if both corners it points toward
+// are, or if the last kick of a quarter turn was used, and otherwise a
+// mini T-spin.
+func (b *Board) Spin(t *Tetromino) Spin {
+	if t.tetro != T || !t.spun {
+		return NoSpin
+	}
+	corners := tCorners[t.rot]
+	filled := [4]bool{}
+	n := 0
+	for i, c := range corners {
+		filled[i] = b.grid.Occupied(t.col+c.Col, t.row+c.Row)
+		if filled[i] {
+			n++
+		}
+	}
+	switch {
+	case n < 3:
+		return NoSpin
+	case filled[0] && filled[1]:
+		return TSpin
+	case t.kick == tstKick && !t.flipped:
+		return TSpin
+	default:
+		return MiniTSpin
+	}
+}
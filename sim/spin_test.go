@@ -0,0 +1,134 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// tsdBoard builds a T-spin double slot at the bottom of the board,
+// with an overhang at column 3 and a T waiting beside it pointing right
+func tsdBoard() (*Board, *Tetromino) {
+	b := NewBoard(10, 20, 0)
+	for col := 0; col < 10; col++ {
+		if col != 4 {
+			b.grid.Set(col, 19, 0)
+		}
+		if col < 3 || col > 5 {
+			b.grid.Set(col, 18, 0)
+		}
+	}
+	b.grid.Set(3, 17, 0)
+	t := &Tetromino{tetro: T, rot: R2}
+	t.moveTo(Cell{Col: 3, Row: 17})
+	return b, t
+}
+
+func Test_Board_Spin(t *testing.T) {
+	cases := []struct {
+		name     string
+		setup    func() (*Board, *Tetromino)
+		expected Spin
+	}{
+		{
+			name: "rotating into the slot is a t-spin",
+			setup: func() (*Board, *Tetromino) {
+				b, t := tsdBoard()
+				b.RotateRight(t)
+				return b, t
+			},
+			expected: TSpin,
+		},
+		{
+			name:     "dropped into the slot without rotating",
+			setup:    tsdBoard,
+			expected: NoSpin,
+		},
+		{
+			name: "only the corner behind the T is open",
+			setup: func() (*Board, *Tetromino) {
+				b := NewBoard(10, 20, 0)
+				b.grid.Set(2, 18, 0)
+				t := &Tetromino{tetro: T, rot: R1}
+				t.moveTo(Cell{Col: 0, Row: 18})
+				t.rotateTo(R1, 0, 0, 0)
+				return b, t
+			},
+			expected: MiniTSpin,
+		},
+		{
+			name: "the last kick makes a mini a full t-spin",
+			setup: func() (*Board, *Tetromino) {
+				b := NewBoard(10, 20, 0)
+				b.grid.Set(2, 18, 0)
+				t := &Tetromino{tetro: T, rot: R1}
+				t.moveTo(Cell{Col: 0, Row: 18})
+				t.rotateTo(R1, tstKick, 0, 0)
+				return b, t
+			},
+			expected: TSpin,
+		},
+		{
+			name: "two corners on the floor",
+			setup: func() (*Board, *Tetromino) {
+				b := NewBoard(10, 20, 0)
+				t := &Tetromino{tetro: T, rot: R1}
+				t.moveTo(Cell{Col: 4, Row: 18})
+				t.rotateTo(R1, 0, 0, 0)
+				return b, t
+			},
+			expected: NoSpin,
+		},
+		{
+			name: "only the T spins",
+			setup: func() (*Board, *Tetromino) {
+				b, t := tsdBoard()
+				t.tetro = S
+				t.rotateTo(R3, 0, 0, 0)
+				return b, t
+			},
+			expected: NoSpin,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			b, tet := c.setup()
+			assert.Equal(t, c.expected, b.Spin(tet))
+		})
+	}
+}
+
+func Test_Board_Spin_HalfTurn(t *testing.T) {
+	// only the fifth kick of the half turn fits, lifting the T two rows
+	// with one of the corners it points toward filled
+	b := NewBoard(10, 20, 0)
+	b.grid.Set(4, 17, 0)
+	b.grid.Set(4, 18, 0)
+	b.grid.Set(6, 15, 0)
+	b.grid.Set(6, 17, 0)
+	tet := &Tetromino{tetro: T, rot: R2}
+	tet.moveTo(Cell{Col: 4, Row: 17})
+	kick, ok := b.Rotate180(tet)
+	assert.True(t, ok)
+	assert.Equal(t, tstKick, kick)
+	assert.Equal(t, R4, tet.rot)
+	assert.Equal(t, MiniTSpin, b.Spin(tet), "only the last kick of a quarter turn makes a full t-spin")
+}
+
+func Test_Board_ClearLines(t *testing.T) {
+	b, tet := tsdBoard()
+	_, ok := b.RotateRight(tet)
+	assert.True(t, ok)
+	b.HardDrop(tet)
+	b.Lock(tet)
+	assert.Equal(t, Cleared{Rows: []int{18, 19}, Spin: TSpin}, b.ClearLines(tet))
+	assert.Equal(t, []Mark{{Cell: Cell{3, 19}}}, b.Marks())
+
+	// moving after the rotation loses the spin
+	b, tet = tsdBoard()
+	b.RotateRight(tet)
+	tet.MoveRight()
+	tet.MoveLeft()
+	b.Lock(tet)
+	assert.Equal(t, NoSpin, b.ClearLines(tet).Spin)
+}
@@ -0,0 +1,60 @@
+package sim
+
+import (
+	"time"
+
+	"github.com/lcaballero/ebiten-01/shapes"
+)
+
+// State is a snapshot of the simulation that renderers and tests read
+// instead of reaching into the Board and Tetromino
+type State struct {
+	Tick    int
+	Paused  bool
+	Over    bool
+	TopOut  TopOut
+	Mode    Mode
+	Time    time.Duration // played, not counting while paused
+	Score   ScoreBoard
+	Cols    int
+	Rows    int
+	Current Piece
+	Ghost   Piece   // the current piece where it would land
+	Queue   []Piece // upcoming pieces, next first
+	Hold    *Piece  // nil until a piece is held
+	Held    bool    // hold was used for the current piece
+	Marks   []Mark
+}
+
+// Cell is the column and row of a block where row 0 is the top of the
+// board
+type Cell struct {
+	Col int
+	Row int
+}
+
+// Piece is a snapshot of a Tetromino where Shape holds the offsets, in
+// cells, of each block from the Cell of the Piece
+type Piece struct {
+	Cell
+	Tetro Tetro
+	Rot   Rotation
+	Skin  int
+	Shape shapes.Vecs
+}
+
+// Cells reports the board cell of each block of the Piece
+func (p Piece) Cells() []Cell {
+	cells := make([]Cell, len(p.Shape))
+	for i, s := range p.Shape {
+		x, y := s.IntComponents()
+		cells[i] = Cell{Col: p.Col + x, Row: p.Row + y}
+	}
+	return cells
+}
+
+// Mark is a snapshot of a single block locked into the Board
+type Mark struct {
+	Cell
+	Skin int
+}
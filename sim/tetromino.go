@@ -0,0 +1,103 @@
+package sim
+
+import (
+	"time"
+
+	"github.com/lcaballero/ebiten-01/shapes"
+)
+
+// Tetromino is the falling piece where col and row locate the origin
+// of its shape and fall accumulates the fraction of a cell it has
+// dropped
+type Tetromino struct {
+	skin     int
+	col      int
+	row      int
+	fall     float64
+	tetro    Tetro
+	rot      Rotation
+	velocity shapes.Vec // cells per second
+	lock     LockState
+	lockTime time.Duration // time spent landed since the last reset
+	resets   int           // lock delay resets used at the lowest row
+	lowest   int           // lowest row reached
+	spun     bool          // the last move was a rotation
+	kick     int           // kick test used by the last rotation
+	flipped  bool          // the last rotation was a half turn
+}
+
+// Update accumulates the fall of the Tetromino for the tick, scaling
+// gravity by the given factor while soft dropping
+func (t *Tetromino) Update(elapsed time.Duration, dt, factor float64) {
+	if t.lock == Locked {
+		return
+	}
+	t.fall += t.velocity.Y() * dt * factor
+}
+
+func (t *Tetromino) MoveRight() {
+	if t.lock == Locked {
+		return
+	}
+	t.col++
+	t.spun = false
+}
+
+func (t *Tetromino) MoveLeft() {
+	if t.lock == Locked {
+		return
+	}
+	t.col--
+	t.spun = false
+}
+
+// rotateTo turns the Tetromino and shifts it by the offset of the kick
+// test that made the rotation fit
+func (t *Tetromino) rotateTo(rot Rotation, kick, dc, dr int) {
+	t.flipped = rot == t.rot.Flip(t.tetro)
+	t.rot = rot
+	t.col += dc
+	t.row += dr
+	t.spun = true
+	t.kick = kick
+}
+
+func (t *Tetromino) moveTo(c Cell) {
+	t.col, t.row = c.Col, c.Row
+	t.fall = 0
+	t.spun = false
+	t.resetLock()
+}
+
+func (t *Tetromino) blocks() shapes.Vecs {
+	blks := positions[t.tetro]
+	blk := blks[int(t.rot)-1]
+	return blk
+}
+
+// cellsAt reports the cells covered by the Tetromino at its current
+// position in the given rotation
+func (t *Tetromino) cellsAt(rot Rotation) []Cell {
+	blks := positions[t.tetro][rot.AsIndex()]
+	cells := make([]Cell, len(blks))
+	for i, blk := range blks {
+		x, y := blk.IntComponents()
+		cells[i] = Cell{Col: t.col + x, Row: t.row + y}
+	}
+	return cells
+}
+
+func (t *Tetromino) cells() []Cell {
+	return t.cellsAt(t.rot)
+}
+
+// piece reports the current values of the Tetromino
+func (t *Tetromino) piece() Piece {
+	return Piece{
+		Tetro: t.tetro,
+		Rot:   t.rot,
+		Skin:  t.skin,
+		Cell:  Cell{Col: t.col, Row: t.row},
+		Shape: t.blocks(),
+	}
+}
@@ -2,67 +2,268 @@ package main
 
 import (
 	"bytes"
-	"io/ioutil"
+	"errors"
+	"io"
+	"io/fs"
 	"log"
+	"os"
+	"path/filepath"
 
 	"github.com/hajimehoshi/ebiten/v2/audio"
 	"github.com/hajimehoshi/ebiten/v2/audio/wav"
+	"github.com/lcaballero/ebiten-01/sim"
 )
 
 const sampleRate = 48000
 
+const (
+	// soundsDir holds a <effect>.wav for each effect, and music.wav
+	soundsDir = "./sounds"
+	// fallbackSound is played for the effects without a file
+	fallbackSound = "./jab.wav"
+	// maxVoices is how many times an effect can overlap itself
+	maxVoices = 4
+)
+
+// Effect is a sound played for something that happens in the game
+type Effect int
+
+const (
+	EffectMove     Effect = 1
+	EffectRotate   Effect = 2
+	EffectLock     Effect = 3
+	EffectHardDrop Effect = 4
+	EffectSingle   Effect = 5
+	EffectDouble   Effect = 6
+	EffectTriple   Effect = 7
+	EffectTetris   Effect = 8
+	EffectTSpin    Effect = 9
+	EffectLevelUp  Effect = 10
+	EffectGameOver Effect = 11
+)
+
+func (e Effect) String() string {
+	switch e {
+	case EffectMove:
+		return "move"
+	case EffectRotate:
+		return "rotate"
+	case EffectLock:
+		return "lock"
+	case EffectHardDrop:
+		return "hard-drop"
+	case EffectSingle:
+		return "single"
+	case EffectDouble:
+		return "double"
+	case EffectTriple:
+		return "triple"
+	case EffectTetris:
+		return "tetris"
+	case EffectTSpin:
+		return "t-spin"
+	case EffectLevelUp:
+		return "level-up"
+	case EffectGameOver:
+		return "game-over"
+	default:
+		return "unknown"
+	}
+}
+
+// Effects lists every Effect in the sound bank
+var Effects = []Effect{
+	EffectMove, EffectRotate, EffectLock, EffectHardDrop, EffectSingle,
+	EffectDouble, EffectTriple, EffectTetris, EffectTSpin, EffectLevelUp,
+	EffectGameOver,
+}
+
+// effectsFor picks the effects played for the event, where the lines
+// cleared together, or a spin, each have their own
+func effectsFor(ev sim.Event) []Effect {
+	switch ev.Kind {
+	case sim.PieceMoved:
+		return []Effect{EffectMove}
+	case sim.PieceRotated:
+		return []Effect{EffectRotate}
+	case sim.PieceDropped:
+		if ev.Hard {
+			return []Effect{EffectHardDrop}
+		}
+	case sim.PieceLocked:
+		if ev.Clear.Spin != sim.NoSpin && ev.Clear.Lines == 0 {
+			return []Effect{EffectLock, EffectTSpin}
+		}
+		return []Effect{EffectLock}
+	case sim.LinesCleared:
+		if ev.Clear.Spin != sim.NoSpin {
+			return []Effect{EffectTSpin}
+		}
+		lines := []Effect{EffectSingle, EffectDouble, EffectTriple, EffectTetris}
+		return []Effect{lines[clamp(ev.Clear.Lines, 1, 4)-1]}
+	case sim.LevelUp:
+		return []Effect{EffectLevelUp}
+	case sim.GameOver:
+		if ev.TopOut != sim.Finished {
+			return []Effect{EffectGameOver}
+		}
+	}
+	return nil
+}
+
+// Volumes are the levels of the buses from 0 to 1, where the master
+// bus scales both the music and the effects
+type Volumes struct {
+	Master float64
+	Music  float64
+	SFX    float64
+}
+
+// music is the level the music plays at
+func (v Volumes) music() float64 {
+	return v.Master * v.Music
+}
+
+// sfx is the level the effects play at
+func (v Volumes) sfx() float64 {
+	return v.Master * v.SFX
+}
+
+// Sound is an effect decoded once and played on a new player each
+// time, so that it can overlap itself
 type Sound struct {
-	ctx    *audio.Context
-	player *audio.Player
-	stream *wav.Stream
+	ctx     *audio.Context
+	pcm     []byte
+	playing []*audio.Player
+}
+
+func NewSound(ctx *audio.Context, pcm []byte) *Sound {
+	return &Sound{
+		ctx: ctx,
+		pcm: pcm,
+	}
 }
 
-func NewSound(path string) *Sound {
+// Play starts the sound at the level, cutting off the oldest voice
+// once maxVoices are playing
+func (s *Sound) Play(lvl float64) {
+	playing := []*audio.Player{}
+	for _, p := range s.playing {
+		if p.IsPlaying() {
+			playing = append(playing, p)
+			continue
+		}
+		p.Close()
+	}
+	if len(playing) >= maxVoices {
+		playing[0].Close()
+		playing = playing[1:]
+	}
+	p := s.ctx.NewPlayerFromBytes(s.pcm)
+	p.SetVolume(lvl)
+	p.Play()
+	s.playing = append(playing, p)
+}
+
+// Audio owns the one audio context of the game, playing the effects of
+// the sound bank and looping the music on their buses
+type Audio struct {
+	ctx     *audio.Context
+	bank    map[Effect]*Sound
+	music   *audio.Player // nil without a music file
+	volumes Volumes
+}
+
+// MustLoadAudio reads the sound bank from the sounds dir, where an
+// effect without its own file plays the fallback sound, and the music
+// when there is any
+func MustLoadAudio() *Audio {
+	ctx := audio.CurrentContext()
+	if ctx == nil {
+		ctx = audio.NewContext(sampleRate)
+	}
+	a := &Audio{
+		ctx:     ctx,
+		bank:    map[Effect]*Sound{},
+		volumes: Volumes{Master: 1, Music: 1, SFX: 1},
+	}
+	decoded := map[string][]byte{}
+	for _, e := range Effects {
+		path := filepath.Join(soundsDir, e.String()+".wav")
+		if !exists(path) {
+			path = fallbackSound
+		}
+		if _, ok := decoded[path]; !ok {
+			decoded[path] = mustDecode(path)
+		}
+		a.bank[e] = NewSound(ctx, decoded[path])
+	}
+	path := filepath.Join(soundsDir, "music.wav")
+	if exists(path) {
+		pcm := mustDecode(path)
+		loop := audio.NewInfiniteLoop(bytes.NewReader(pcm), int64(len(pcm)))
+		player, err := ctx.NewPlayer(loop)
+		if err != nil {
+			panic(err)
+		}
+		a.music = player
+	}
+	return a
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return !errors.Is(err, fs.ErrNotExist)
+}
+
+// mustDecode reads the wav file into samples at the sample rate of the
+// context
+func mustDecode(path string) []byte {
 	log.Printf("loading audio: %s", path)
-	bin, err := ioutil.ReadFile(path)
+	bin, err := os.ReadFile(path)
 	if err != nil {
 		panic(err)
 	}
-	context := audio.NewContext(sampleRate)
-	stream, err := wav.DecodeWithoutResampling(bytes.NewReader(bin))
+	stream, err := wav.DecodeWithSampleRate(sampleRate, bytes.NewReader(bin))
 	if err != nil {
 		panic(err)
 	}
-	player, err := context.NewPlayer(stream)
+	pcm, err := io.ReadAll(stream)
 	if err != nil {
 		panic(err)
 	}
-	player.SetVolume(.5)
-	return &Sound{
-		ctx:    context,
-		stream: stream,
-		player: player,
-	}
-}
-
-func (a *Sound) SetVolume(lvl float64) {
-	a.SetVolume(lvl)
+	return pcm
 }
 
-func (a *Sound) Volume() float64 {
-	return a.player.Volume()
+// SetVolumes sets the levels of the buses
+func (a *Audio) SetVolumes(v Volumes) {
+	a.volumes = v
+	if a.music != nil {
+		a.music.SetVolume(v.music())
+	}
 }
 
-func (a *Sound) Play() error {
-	if err := a.player.Rewind(); err != nil {
-		return err
+// Play starts the effect on the SFX bus
+func (a *Audio) Play(e Effect) {
+	if s, ok := a.bank[e]; ok {
+		s.Play(a.volumes.sfx())
 	}
-	a.player.Play()
-	return nil
 }
 
-type Audio struct {
-	jab *Sound
+// PlayMusic loops the music while on, pausing it where it is when off
+func (a *Audio) PlayMusic(on bool) {
+	switch {
+	case a.music == nil:
+	case on:
+		a.music.Play()
+	default:
+		a.music.Pause()
+	}
 }
 
-func MustLoadAudio() *Audio {
-	sound := NewSound("./jab.wav")
-	return &Audio{
-		jab: sound,
+// OnEvent plays the effects for the events of the game
+func (a *Audio) OnEvent(ev sim.Event) {
+	for _, e := range effectsFor(ev) {
+		a.Play(e)
 	}
 }
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/lcaballero/ebiten-01/sim"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_EffectsFor(t *testing.T) {
+	table := []struct {
+		name     string
+		ev       sim.Event
+		expected []Effect
+	}{
+		{name: "move", ev: sim.Event{Kind: sim.PieceMoved}, expected: []Effect{EffectMove}},
+		{name: "rotate", ev: sim.Event{Kind: sim.PieceRotated}, expected: []Effect{EffectRotate}},
+		{name: "soft drop is quiet", ev: sim.Event{Kind: sim.PieceDropped, Cells: 1}},
+		{name: "hard drop", ev: sim.Event{Kind: sim.PieceDropped, Hard: true}, expected: []Effect{EffectHardDrop}},
+		{name: "lock", ev: sim.Event{Kind: sim.PieceLocked}, expected: []Effect{EffectLock}},
+		{
+			name:     "spin without lines",
+			ev:       sim.Event{Kind: sim.PieceLocked, Clear: sim.Clear{Spin: sim.TSpin}},
+			expected: []Effect{EffectLock, EffectTSpin},
+		},
+		{name: "single", ev: sim.Event{Kind: sim.LinesCleared, Clear: sim.Clear{Lines: 1}}, expected: []Effect{EffectSingle}},
+		{name: "tetris", ev: sim.Event{Kind: sim.LinesCleared, Clear: sim.Clear{Lines: 4}}, expected: []Effect{EffectTetris}},
+		{
+			name:     "spin with lines",
+			ev:       sim.Event{Kind: sim.LinesCleared, Clear: sim.Clear{Lines: 2, Spin: sim.TSpin}},
+			expected: []Effect{EffectTSpin},
+		},
+		{name: "level up", ev: sim.Event{Kind: sim.LevelUp}, expected: []Effect{EffectLevelUp}},
+		{name: "top out", ev: sim.Event{Kind: sim.GameOver, TopOut: sim.BlockOut}, expected: []Effect{EffectGameOver}},
+		{name: "finished", ev: sim.Event{Kind: sim.GameOver, TopOut: sim.Finished}},
+		{name: "hold", ev: sim.Event{Kind: sim.HoldUsed}},
+	}
+	for _, row := range table {
+		t.Run(row.name, func(t *testing.T) {
+			assert.Equal(t, row.expected, effectsFor(row.ev))
+		})
+	}
+}
+
+func Test_Effect_String(t *testing.T) {
+	names := map[string]bool{}
+	for _, e := range Effects {
+		assert.NotEqual(t, "unknown", e.String())
+		names[e.String()] = true
+	}
+	assert.Len(t, names, len(Effects))
+}